@@ -2,19 +2,138 @@ package command
 
 import (
 	"alanmitic/gocalc/expreval"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"unicode/utf8"
 )
 
 type Argument struct {
 	token        expreval.LexAnToken
 	textValue    string
 	numericValue float64
+	// Position of the text of a text or expression list argument in the command line, in bytes and in characters, for
+	// showing where an error in it was found.
+	offset       int
+	columnOffset int
+}
+
+// Gets the token type of the argument, e.g. expreval.TokenNumber, or expreval.TokenEnd for the text of a text or
+// expression list command.
+func (argument Argument) GetToken() expreval.LexAnToken {
+	return argument.token
+}
+
+// Gets the text of an identifier, variable or text argument.
+func (argument Argument) GetTextValue() string {
+	return argument.textValue
+}
+
+// Gets the value of a number argument.
+func (argument Argument) GetNumericValue() float64 {
+	return argument.numericValue
+}
+
+// Gets the value of a number argument as an integer, e.g. a precision.  Returns ErrInvalidArgs if the value is not a
+// whole number from min to max.
+func (argument Argument) GetIntegerValue(min int, max int) (int, error) {
+	value := argument.numericValue
+	if value != math.Trunc(value) || value < float64(min) || value > float64(max) {
+		return 0, ErrInvalidArgs
+	}
+	return int(value), nil
+}
+
+// Gets the text of the argument from the byte offset onwards as an argument, with its position in the command line.
+func (argument Argument) textFrom(offset int) Argument {
+	text := argument.textValue
+	return Argument{expreval.TokenEnd, text[offset:], 0, argument.offset + offset,
+		argument.columnOffset + utf8.RuneCountInString(text[:offset])}
+}
+
+// Moves the position of an evaluation error in the text of the argument to its position in the command line.
+func (argument Argument) positionError(err error) error {
+	var evaluationError *expreval.EvaluationError
+	if errors.As(err, &evaluationError) {
+		evaluationError.Start += argument.offset
+		evaluationError.End += argument.offset
+		evaluationError.Column += argument.columnOffset
+	}
+	return err
 }
 
 type Signature []expreval.LexAnToken
 
+// Parameter of a signature for a number that may be given as an expression, e.g. "fix $digits + 1".  It must be the
+// last parameter, and takes the remainder of the line, which is evaluated before the command is executed.  Other
+// parameters, such as keywords, are literal tokens.
+const ParameterExpression expreval.LexAnToken = -1
+
+// A command, such as "fix".  Any output from executing the command is written to the supplied writer.
 type Command interface {
 	GetName() string
 	GetSignatures() []Signature
-	Execute(arguments []Argument) error
+	Execute(arguments []Argument, output io.Writer) error
 	GetUsage() (string, string)
 }
+
+// A command whose arguments are a comma separated list of expressions.  The remainder of the input line is split at
+// the top level commas and each part is passed to Execute as an argument holding the unparsed expression text.
+type ExpressionListCommand interface {
+	Command
+	GetMinExpressions() int
+}
+
+// A command whose argument is the remainder of the input line, such as a file name.  The text is passed to Execute as a
+// single argument with the surrounding whitespace removed, or as no arguments when it is empty.
+type TextCommand interface {
+	Command
+	IsTextRequired() bool
+}
+
+// A command whose identifier arguments are one of a set of keywords, such as "on" or "off".  The keywords are offered
+// when completing the command's arguments.
+type KeywordCommand interface {
+	Command
+	GetKeywords() []string
+}
+
+// A command with a detailed description and examples of its use, shown by "help <command>".
+type HelpCommand interface {
+	Command
+	GetHelp() (string, []string)
+}
+
+// A command whose last parameter may be repeated, such as "unset $a $b".  The last parameter of each signature matches
+// any further arguments of the same type.
+type VariadicCommand interface {
+	Command
+	IsVariadic() bool
+}
+
+// Error for invalid command arguments, which includes the usage of the command.
+type UsageError struct {
+	Usage string
+	Err   error
+}
+
+func (usageError *UsageError) Error() string {
+	return fmt.Sprintf("%v, usage: %s", usageError.Err, usageError.Usage)
+}
+
+func (usageError *UsageError) Unwrap() error {
+	return usageError.Err
+}
+
+// Wraps an error for invalid arguments to the command with the command's usage.  Other errors, such as a file that
+// cannot be read, are returned as they are.
+func newUsageError(command Command, err error) error {
+	var usageError *UsageError
+	if errors.As(err, &usageError) || !(errors.Is(err, ErrInvalidArgs) || errors.Is(err, ErrTooManyArgs)) {
+		return err
+	}
+
+	usageSyntax, _ := command.GetUsage()
+	return &UsageError{usageSyntax, err}
+}
@@ -0,0 +1,41 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"io"
+)
+
+type CommandAlg struct {
+	scriptRunner *ScriptRunner
+}
+
+func (commandAlg *CommandAlg) GetName() string {
+	return "alg"
+}
+
+func (commandAlg *CommandAlg) GetSignatures() []Signature {
+	return []Signature{
+		// alg
+		[]expreval.LexAnToken{}}
+}
+
+func (commandAlg *CommandAlg) Execute(arguments []Argument, output io.Writer) error {
+	commandAlg.scriptRunner.RPN = false
+	return nil
+}
+
+func (commandAlg *CommandAlg) GetUsage() (string, string) {
+	return "alg", "Set algebraic input, the default."
+}
+
+func (commandAlg *CommandAlg) GetHelp() (string, []string) {
+	description := "Switch back to algebraic input after rpn, so that lines are evaluated as expressions such as 3 + " +
+		"4 * 2.  The RPN stack is kept for when rpn is used again."
+	return description, []string{"alg"}
+}
+
+func NewCommandAlg(scriptRunner *ScriptRunner) Command {
+	command := CommandAlg{}
+	command.scriptRunner = scriptRunner
+	return &command
+}
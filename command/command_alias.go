@@ -0,0 +1,75 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"fmt"
+	"io"
+	"sort"
+)
+
+type CommandAlias struct {
+	commandParser *CommandParser
+}
+
+func (commandAlias *CommandAlias) GetName() string {
+	return "alias"
+}
+
+func (commandAlias *CommandAlias) GetSignatures() []Signature {
+	return []Signature{}
+}
+
+func (commandAlias *CommandAlias) IsTextRequired() bool {
+	// alias [name = command]
+	return false
+}
+
+func (commandAlias *CommandAlias) Execute(arguments []Argument, output io.Writer) error {
+	if len(arguments) == 1 {
+		lexAn := expreval.CreateLexicalAnalyser(arguments[0].textValue)
+		if lexAn.ParseNextToken() != expreval.TokenIdentifier {
+			return ErrInvalidArgs
+		}
+
+		name := lexAn.GetTextValue()
+		if lexAn.ParseNextToken() != expreval.TokenOpAssign {
+			return ErrInvalidArgs
+		}
+
+		return commandAlias.commandParser.AddAlias(name, lexAn.GetRemainingInput())
+	}
+
+	aliases := commandAlias.commandParser.GetAliases()
+	if len(aliases) == 0 {
+		fmt.Fprintln(output, "No aliases defined!")
+		return nil
+	}
+
+	names := []string{}
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintln(output, "alias", name, "=", aliases[name])
+	}
+
+	return nil
+}
+
+func (commandAlias *CommandAlias) GetUsage() (string, string) {
+	return "alias <name> = <command>", "Define another name for a command, or list the aliases."
+}
+
+func (commandAlias *CommandAlias) GetHelp() (string, []string) {
+	description := "Define a name that is replaced by the start of a command line, which may include arguments.  " +
+		"The name cannot be a command or macro.  With no name the aliases are listed."
+	return description, []string{"alias", "alias h = hex", "alias f4 = fix 4"}
+}
+
+func NewCommandAlias(commandParser *CommandParser) Command {
+	command := CommandAlias{}
+	command.commandParser = commandParser
+	return &command
+}
@@ -0,0 +1,113 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"alanmitic/gocalc/resultformatter"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestCommandAlias(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	command, arguments, _ := commandParser.ParseCommand("alias h = hex")
+	assertCommand(t, command, "alias")
+	err := command.Execute(arguments, io.Discard)
+	if err != nil {
+		t.Error("Expected:", nil, "Actual:", err)
+	}
+
+	command, _, _ = commandParser.ParseCommand("h")
+	assertCommand(t, command, "hex")
+
+	// An alias may include arguments, and further arguments follow them.
+	commandParser.AddAlias("f", "fix")
+	command, arguments, _ = commandParser.ParseCommand("f 4")
+	command.Execute(arguments, io.Discard)
+	assertOutputModeAndPrecision(t, resultFormatter, resultformatter.OutputModeFixed, 4)
+
+	output := new(bytes.Buffer)
+	command, arguments, _ = commandParser.ParseCommand("alias")
+	command.Execute(arguments, output)
+	assertOutput(t, output, "alias f = fix\nalias h = hex\n")
+
+	command, arguments, _ = commandParser.ParseCommand("unalias h")
+	command.Execute(arguments, io.Discard)
+	command, _, err = commandParser.ParseCommand("h")
+	assertNilCommandAndError(t, command, err, ErrAmbiguousCommand)
+}
+
+func TestCommandAliasErrors(t *testing.T) {
+	commandParser := NewCommandParser(expreval.NewEvaluator(), resultformatter.NewResultFormatter())
+
+	err := commandParser.AddAlias("sci", "fix")
+	if !errors.Is(err, ErrNameConflict) {
+		t.Error("Expected:", ErrNameConflict, "Actual:", err)
+	}
+
+	err = commandParser.AddAlias("x", "nothing")
+	if !errors.Is(err, ErrNotFound) {
+		t.Error("Expected:", ErrNotFound, "Actual:", err)
+	}
+
+	for _, input := range []string{"alias x", "alias $x = fix", "alias inf = fix"} {
+		command, arguments, _ := commandParser.ParseCommand(input)
+		err := command.Execute(arguments, io.Discard)
+		if !errors.Is(err, ErrInvalidArgs) {
+			t.Error("Input:", input, "Expected:", ErrInvalidArgs, "Actual:", err)
+		}
+	}
+
+	command, arguments, _ := commandParser.ParseCommand("unalias x")
+	err = command.Execute(arguments, io.Discard)
+	if !errors.Is(err, ErrNotFound) {
+		t.Error("Expected:", ErrNotFound, "Actual:", err)
+	}
+
+	// The position of an error is shown in the line with the alias replaced.
+	commandParser.AddAlias("f", "fix 1 +")
+	err = commandParser.GetScriptRunner().ExecuteLine("f $zz")
+	output := new(bytes.Buffer)
+	PrintError(output, err, "f $zz")
+	assertOutput(t, output, "ERROR: undefined variable $zz at column 10, usage: fix <precision>\n"+
+		"  fix 1 +  $zz\n"+
+		"           ^\n")
+}
+
+func TestParseCommandPrefix(t *testing.T) {
+	commandParser := NewCommandParser(expreval.NewEvaluator(), resultformatter.NewResultFormatter())
+
+	command, arguments, err := commandParser.ParseCommand("sc 3")
+	if err != nil {
+		t.Error("Expected:", nil, "Actual:", err)
+	}
+	assertCommand(t, command, "sci")
+	assertArguments(t, arguments, []Argument{{expreval.TokenNumber, "", 3.0, 0, 0}})
+
+	// An exact name is used before the longer names it is a prefix of.
+	command, _, _ = commandParser.ParseCommand("alias")
+	assertCommand(t, command, "alias")
+
+	command, _, err = commandParser.ParseCommand("s")
+	assertNilCommandAndError(t, command, err, ErrAmbiguousCommand)
+	if err.Error() != "ambiguous command: s could be save, sci, source, strict" {
+		t.Error("Expected:", "ambiguous command: s could be save, sci, source, strict", "Actual:", err)
+	}
+
+	command, _, err = commandParser.ParseCommand("zz")
+	assertNilCommandAndError(t, command, err, ErrNotFound)
+
+	// Commands that end the session or remove state must be given in full.
+	for _, input := range []string{"e", "ex", "cle"} {
+		command, _, err = commandParser.ParseCommand(input)
+		assertNilCommandAndError(t, command, err, ErrNotFound)
+	}
+
+	command, _, err = commandParser.ParseCommand("c")
+	if err.Error() != "ambiguous command: c could be const, continue" {
+		t.Error("Expected:", "ambiguous command: c could be const, continue", "Actual:", err)
+	}
+}
@@ -3,6 +3,7 @@ package command
 import (
 	"alanmitic/gocalc/expreval"
 	"alanmitic/gocalc/resultformatter"
+	"io"
 )
 
 type CommandBin struct {
@@ -19,7 +20,7 @@ func (commandBin *CommandBin) GetSignatures() []Signature {
 		[]expreval.LexAnToken{}}
 }
 
-func (commandBin *CommandBin) Execute(arguments []Argument) error {
+func (commandBin *CommandBin) Execute(arguments []Argument, output io.Writer) error {
 	commandBin.resultFormatter.SetOutputMode(resultformatter.OutputModeBinary)
 	return nil
 }
@@ -28,6 +29,12 @@ func (commandBin *CommandBin) GetUsage() (string, string) {
 	return "bin", "Set binary output mode."
 }
 
+func (commandBin *CommandBin) GetHelp() (string, []string) {
+	description := "Output results in binary, as the lower 32 bits of the integer part of the value.  Special values " +
+		"are still output as inf, -inf and nan."
+	return description, []string{"bin", "b$1010 + 1"}
+}
+
 func NewCommandBin(resultFormatter resultformatter.ResultFormatter) Command {
 	command := CommandBin{}
 	command.resultFormatter = resultFormatter
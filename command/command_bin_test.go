@@ -3,6 +3,7 @@ package command
 import (
 	"alanmitic/gocalc/expreval"
 	"alanmitic/gocalc/resultformatter"
+	"io"
 	"testing"
 )
 
@@ -14,7 +15,7 @@ func TestCommandBin(t *testing.T) {
 	assertCommand(t, command, "bin")
 	assertArguments(t, arguments, []Argument{})
 
-	command.Execute(arguments)
+	command.Execute(arguments, io.Discard)
 	assertOutputModeAndPrecision(t, resultFormatter, resultformatter.OutputModeBinary, -1)
 }
 
@@ -0,0 +1,57 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"io"
+)
+
+type CommandClear struct {
+	evaluator *expreval.Evaluator
+}
+
+func (commandClear *CommandClear) GetName() string {
+	return "clear"
+}
+
+func (commandClear *CommandClear) GetSignatures() []Signature {
+	return []Signature{
+		// clear <vars|history>
+		[]expreval.LexAnToken{expreval.TokenIdentifier}}
+}
+
+func (commandClear *CommandClear) Execute(arguments []Argument, output io.Writer) error {
+	if len(arguments) != 1 {
+		return ErrInvalidArgs
+	}
+
+	switch arguments[0].textValue {
+	case "vars":
+		commandClear.evaluator.ClearVariables()
+	case "history":
+		commandClear.evaluator.History = []expreval.HistoryEntry{}
+	default:
+		return ErrInvalidArgs
+	}
+
+	return nil
+}
+
+func (commandClear *CommandClear) GetKeywords() []string {
+	return []string{"vars", "history"}
+}
+
+func (commandClear *CommandClear) GetUsage() (string, string) {
+	return "clear <vars|history>", "Remove the variables, except $ans and the constants, or the history."
+}
+
+func (commandClear *CommandClear) GetHelp() (string, []string) {
+	description := "Remove all the variables except $ans and the constants with clear vars, or the results in the " +
+		"history with clear history.  In RPN mode clear on its own removes the values on the stack."
+	return description, []string{"clear vars", "clear history"}
+}
+
+func NewCommandClear(evaluator *expreval.Evaluator) Command {
+	command := CommandClear{}
+	command.evaluator = evaluator
+	return &command
+}
@@ -0,0 +1,40 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"alanmitic/gocalc/resultformatter"
+	"io"
+	"testing"
+)
+
+func TestCommandClear(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	scriptRunner := commandParser.GetScriptRunner()
+	scriptRunner.Output = io.Discard
+	for _, line := range []string{"$a = 1", "$b = 2", "const $g = 9.80665", "clear vars"} {
+		err := scriptRunner.ExecuteLine(line)
+		if err != nil {
+			t.Fatal("Line:", line, "Expected:", nil, "Actual:", err)
+		}
+	}
+
+	if len(evaluator.GetVariableNames()) != 2 {
+		t.Error("Expected:", "$ans and $g", "Actual:", evaluator.GetVariableNames())
+	}
+	assertScriptVariable(t, evaluator, "$ans", 2)
+	assertScriptVariable(t, evaluator, "$g", 9.80665)
+
+	command, arguments, _ := commandParser.ParseCommand("clear history")
+	command.Execute(arguments, io.Discard)
+	if len(evaluator.History) != 0 {
+		t.Error("Expected:", 0, "Actual:", len(evaluator.History))
+	}
+
+	command, arguments, _ = commandParser.ParseCommand("clear all")
+	err := command.Execute(arguments, io.Discard)
+	if err != ErrInvalidArgs {
+		t.Error("Expected:", ErrInvalidArgs, "Actual:", err)
+	}
+}
@@ -0,0 +1,77 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"fmt"
+	"io"
+)
+
+type CommandConst struct {
+	evaluator *expreval.Evaluator
+}
+
+func (commandConst *CommandConst) GetName() string {
+	return "const"
+}
+
+func (commandConst *CommandConst) GetSignatures() []Signature {
+	return []Signature{}
+}
+
+func (commandConst *CommandConst) IsTextRequired() bool {
+	// const <variable> = <expression>
+	return true
+}
+
+func (commandConst *CommandConst) Execute(arguments []Argument, output io.Writer) error {
+	if len(arguments) != 1 {
+		return ErrInvalidArgs
+	}
+
+	lexAn := expreval.CreateLexicalAnalyser(arguments[0].textValue)
+	if lexAn.ParseNextToken() != expreval.TokenVariable {
+		return ErrInvalidArgs
+	}
+
+	name := lexAn.GetTextValue()
+	if lexAn.ParseNextToken() != expreval.TokenOpAssign {
+		return ErrInvalidArgs
+	}
+
+	if !expreval.IsAssignableVariable(name) {
+		return fmt.Errorf("%w: %s", expreval.ErrInvalidVariable, name)
+	}
+
+	if commandConst.evaluator.IsConstant(name) {
+		return fmt.Errorf("%w: %s", expreval.ErrConstantAssignment, name)
+	}
+
+	// Defining a constant is not a result, so $ans and the history are left as they were.
+	text := arguments[0].textValue
+	expression := arguments[0].textFrom(len(text) - len(lexAn.GetRemainingInput()))
+	restore := preserveEvaluatorState(commandConst.evaluator, "$ans")
+	value, err := commandConst.evaluator.Evaluate(expression.textValue)
+	restore()
+	if err != nil {
+		return expression.positionError(err)
+	}
+
+	return commandConst.evaluator.SetConstant(name, value)
+}
+
+func (commandConst *CommandConst) GetUsage() (string, string) {
+	return "const <variable> = <expression>", "Define a constant, a variable that cannot be assigned again."
+}
+
+func (commandConst *CommandConst) GetHelp() (string, []string) {
+	description := "Define a constant, a variable whose value is fixed.  Assigning it again is an error, in an " +
+		"expression, in RPN mode or with another const, but it can be removed with unset.  Constants are " +
+		"kept by clear vars and shown as const by vars."
+	return description, []string{"const $g = 9.80665", "const $c = 299792458"}
+}
+
+func NewCommandConst(evaluator *expreval.Evaluator) Command {
+	command := CommandConst{}
+	command.evaluator = evaluator
+	return &command
+}
@@ -0,0 +1,64 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"alanmitic/gocalc/resultformatter"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestCommandConst(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	command, arguments, _ := commandParser.ParseCommand("const $g = 9.80665")
+	assertCommand(t, command, "const")
+	assertArguments(t, arguments, []Argument{{expreval.TokenEnd, "$g = 9.80665", 0.0, 0, 0}})
+
+	err := command.Execute(arguments, io.Discard)
+	if err != nil {
+		t.Error("Expected:", nil, "Actual:", err)
+	}
+
+	assertScriptVariable(t, evaluator, "$g", 9.80665)
+	if _, found := evaluator.Variables.GetVariable("$ans"); found || len(evaluator.History) != 0 {
+		t.Error("Expected:", "no result", "Actual:", evaluator.GetVariableNames(), evaluator.History)
+	}
+
+	err = command.Execute(arguments, io.Discard)
+	if !errors.Is(err, expreval.ErrConstantAssignment) {
+		t.Error("Expected:", expreval.ErrConstantAssignment, "Actual:", err)
+	}
+
+	_, err = evaluator.Evaluate("$g = 10")
+	if !errors.Is(err, expreval.ErrConstantAssignment) {
+		t.Error("Expected:", expreval.ErrConstantAssignment, "Actual:", err)
+	}
+}
+
+func TestCommandConstInvalidArgs(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+
+	for _, input := range []string{"const $g", "const g = 1", "const $ans = 1", "const $g = 1 +"} {
+		command, arguments, _ := commandParser.ParseCommand(input)
+		err := command.Execute(arguments, io.Discard)
+		if err == nil {
+			t.Error("Input:", input, "Expected:", "error", "Actual:", err)
+		}
+	}
+
+	command, _, err := commandParser.ParseCommand("const")
+	assertNilCommandAndError(t, command, err, ErrInvalidArgs)
+
+	// The position of an error in the expression is shown in the line.
+	err = commandParser.GetScriptRunner().ExecuteLine("const  $g = 1 + $zz")
+	output := new(bytes.Buffer)
+	PrintError(output, err, "const  $g = 1 + $zz")
+	assertOutput(t, output, "ERROR: undefined variable $zz at column 17\n"+
+		"  const  $g = 1 + $zz\n"+
+		"                  ^\n")
+}
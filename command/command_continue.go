@@ -0,0 +1,66 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"fmt"
+	"io"
+)
+
+type CommandContinue struct {
+	evaluator *expreval.Evaluator
+}
+
+func (commandContinue *CommandContinue) GetName() string {
+	return "continue"
+}
+
+func (commandContinue *CommandContinue) GetSignatures() []Signature {
+	return []Signature{
+		// continue
+		[]expreval.LexAnToken{},
+		// continue <on|off>
+		[]expreval.LexAnToken{expreval.TokenIdentifier}}
+}
+
+func (commandContinue *CommandContinue) Execute(arguments []Argument, output io.Writer) error {
+	if len(arguments) == 0 {
+		if commandContinue.evaluator.ContinueFromAns {
+			fmt.Fprintln(output, "continue on")
+		} else {
+			fmt.Fprintln(output, "continue off")
+		}
+		return nil
+	}
+
+	switch arguments[0].textValue {
+	case "on":
+		commandContinue.evaluator.ContinueFromAns = true
+	case "off":
+		commandContinue.evaluator.ContinueFromAns = false
+	default:
+		return ErrInvalidArgs
+	}
+
+	return nil
+}
+
+func (commandContinue *CommandContinue) GetKeywords() []string {
+	return []string{"on", "off"}
+}
+
+func (commandContinue *CommandContinue) GetUsage() (string, string) {
+	return "continue <on|off>", "Set or show whether input starting with an operator continues from $ans."
+}
+
+func (commandContinue *CommandContinue) GetHelp() (string, []string) {
+	description := "Set whether input that starts with an operator other than minus continues from the previous " +
+		"result, so that * 2 doubles $ans.  With no argument the current setting is shown.  It is on by " +
+		"default."
+	return description, []string{"continue", "continue off"}
+}
+
+func NewCommandContinue(evaluator *expreval.Evaluator) Command {
+	command := CommandContinue{}
+	command.evaluator = evaluator
+	return &command
+}
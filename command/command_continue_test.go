@@ -0,0 +1,40 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"alanmitic/gocalc/resultformatter"
+	"io"
+	"testing"
+)
+
+func TestCommandContinueOff(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	command, arguments, _ := commandParser.ParseCommand("continue off")
+	assertCommand(t, command, "continue")
+	assertArguments(t, arguments, []Argument{{expreval.TokenIdentifier, "off", 0.0, 0, 0}})
+
+	command.Execute(arguments, io.Discard)
+	if evaluator.ContinueFromAns {
+		t.Error("Expected:", false, "Actual:", evaluator.ContinueFromAns)
+	}
+
+	command, arguments, _ = commandParser.ParseCommand("continue on")
+	command.Execute(arguments, io.Discard)
+	if !evaluator.ContinueFromAns {
+		t.Error("Expected:", true, "Actual:", evaluator.ContinueFromAns)
+	}
+}
+
+func TestCommandContinueInvalidArgs(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	command, arguments, _ := commandParser.ParseCommand("continue maybe")
+
+	err := command.Execute(arguments, io.Discard)
+	if err != ErrInvalidArgs {
+		t.Error("Expected:", ErrInvalidArgs, "Actual:", err)
+	}
+}
@@ -2,9 +2,30 @@ package command
 
 import (
 	"alanmitic/gocalc/expreval"
-	"os"
+	"errors"
+	"fmt"
+	"io"
 )
 
+var ErrExit = errors.New("exit requested")
+
+// Largest exit code, as the exit status of a process is a byte.
+const MaxExitCode = 255
+
+// Error returned by the exit command, so that the caller can clean up before exiting with the exit code.  It matches
+// ErrExit with errors.Is.
+type ExitError struct {
+	Code int
+}
+
+func (exitError *ExitError) Error() string {
+	return fmt.Sprintf("exit %d", exitError.Code)
+}
+
+func (exitError *ExitError) Is(target error) bool {
+	return target == ErrExit
+}
+
 type CommandExit struct {
 }
 
@@ -15,16 +36,32 @@ func (commandExit *CommandExit) GetName() string {
 func (commandExit *CommandExit) GetSignatures() []Signature {
 	return []Signature{
 		// exit
-		[]expreval.LexAnToken{}}
+		[]expreval.LexAnToken{},
+		// exit <code>
+		[]expreval.LexAnToken{ParameterExpression}}
 }
 
-func (commandExit *CommandExit) Execute(arguments []Argument) error {
-	os.Exit(0)
-	return nil
+func (commandExit *CommandExit) Execute(arguments []Argument, output io.Writer) error {
+	code := 0
+	if len(arguments) == 1 {
+		var err error
+		code, err = arguments[0].GetIntegerValue(0, MaxExitCode)
+		if err != nil {
+			return err
+		}
+	}
+
+	return &ExitError{code}
 }
 
 func (commandExit *CommandExit) GetUsage() (string, string) {
-	return "exit", "Exit application."
+	return "exit <code>", "Exit application with optional exit code."
+}
+
+func (commandExit *CommandExit) GetHelp() (string, []string) {
+	description := "Exit gocalc, with the exit status given from 0 to 255, or 0.  In a script exit also stops the " +
+		"script."
+	return description, []string{"exit", "exit 1"}
 }
 
 func NewCommandExit() Command {
@@ -3,6 +3,8 @@ package command
 import (
 	"alanmitic/gocalc/expreval"
 	"alanmitic/gocalc/resultformatter"
+	"errors"
+	"io"
 	"testing"
 )
 
@@ -22,3 +24,36 @@ func TestCommandExitWithTooManyArgs(t *testing.T) {
 	command, _, err := commandParser.ParseCommand("exit 2 5")
 	assertNilCommandAndError(t, command, err, ErrTooManyArgs)
 }
+
+func TestCommandExitWithCode(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	command, arguments, _ := commandParser.ParseCommand("exit 3")
+	assertArguments(t, arguments, []Argument{{expreval.TokenNumber, "", 3.0, 0, 0}})
+
+	err := command.Execute(arguments, io.Discard)
+	var exitError *ExitError
+	if !errors.Is(err, ErrExit) || !errors.As(err, &exitError) {
+		t.Fatal("Expected:", ErrExit, "Actual:", err)
+	}
+
+	if exitError.Code != 3 {
+		t.Error("Expected:", 3, "Actual:", exitError.Code)
+	}
+}
+
+func TestCommandExitWithInvalidCode(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+
+	// The exit code must be a whole number from 0 to 255.
+	for _, line := range []string{"exit 1.5", "exit 256", "exit -1", "exit 2 ^ 63"} {
+		command, arguments, _ := commandParser.ParseCommand(line)
+		err := command.Execute(arguments, io.Discard)
+		if err != ErrInvalidArgs {
+			t.Error("Line:", line, "Expected:", ErrInvalidArgs, "Actual:", err)
+		}
+	}
+}
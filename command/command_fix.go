@@ -3,6 +3,7 @@ package command
 import (
 	"alanmitic/gocalc/expreval"
 	"alanmitic/gocalc/resultformatter"
+	"io"
 )
 
 const (
@@ -22,14 +23,19 @@ func (commandFix *CommandFix) GetSignatures() []Signature {
 		// fix
 		[]expreval.LexAnToken{},
 		// fix <n>
-		[]expreval.LexAnToken{expreval.TokenNumber}}
+		[]expreval.LexAnToken{ParameterExpression}}
 }
 
-func (commandFix *CommandFix) Execute(arguments []Argument) error {
+func (commandFix *CommandFix) Execute(arguments []Argument, output io.Writer) error {
 	precision := DefaultFixPrecision
 	if len(arguments) == 1 {
-		precision = int(arguments[0].numericValue)
+		var err error
+		precision, err = arguments[0].GetIntegerValue(0, resultformatter.MaxPrecision)
+		if err != nil {
+			return err
+		}
 	}
+
 	commandFix.resultFormatter.SetOutputMode(resultformatter.OutputModeFixed)
 	commandFix.resultFormatter.SetPrecision(precision)
 	return nil
@@ -39,6 +45,12 @@ func (commandFix *CommandFix) GetUsage() (string, string) {
 	return "fix <precision>", "Set fix point output mode with optional precision."
 }
 
+func (commandFix *CommandFix) GetHelp() (string, []string) {
+	description := "Output results in fixed point notation, with the precision as the number of decimal places from " +
+		"0 to 17, 2 if it is not given."
+	return description, []string{"fix", "fix 4", "fix $digits + 1"}
+}
+
 func NewCommandFix(resultFormatter resultformatter.ResultFormatter) Command {
 	command := CommandFix{}
 	command.resultFormatter = resultFormatter
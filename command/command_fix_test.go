@@ -3,6 +3,7 @@ package command
 import (
 	"alanmitic/gocalc/expreval"
 	"alanmitic/gocalc/resultformatter"
+	"io"
 	"testing"
 )
 
@@ -14,7 +15,7 @@ func TestCommandFixNoPrecision(t *testing.T) {
 	assertCommand(t, command, "fix")
 	assertArguments(t, arguments, []Argument{})
 
-	command.Execute(arguments)
+	command.Execute(arguments, io.Discard)
 	assertOutputModeAndPrecision(t, resultFormatter, resultformatter.OutputModeFixed, 2)
 }
 
@@ -24,9 +25,9 @@ func TestCommandFixWithPrecision(t *testing.T) {
 	commandParser := NewCommandParser(evaluator, resultFormatter)
 	command, arguments, _ := commandParser.ParseCommand("fix 4")
 	assertCommand(t, command, "fix")
-	assertArguments(t, arguments, []Argument{{expreval.TokenNumber, "", 4.0}})
+	assertArguments(t, arguments, []Argument{{expreval.TokenNumber, "", 4.0, 0, 0}})
 
-	command.Execute(arguments)
+	command.Execute(arguments, io.Discard)
 	assertOutputModeAndPrecision(t, resultFormatter, resultformatter.OutputModeFixed, 4)
 }
 
@@ -37,3 +38,20 @@ func TestCommandFixWithTooManyArgs(t *testing.T) {
 	command, _, err := commandParser.ParseCommand("fix 2 5")
 	assertNilCommandAndError(t, command, err, ErrTooManyArgs)
 }
+
+func TestCommandFixWithInvalidPrecision(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+
+	// The precision must be a whole number from 0 to 17.
+	for _, line := range []string{"fix 2 ^ 31", "fix 2 ^ 63", "fix -2", "fix 2.5", "fix 18"} {
+		command, arguments, _ := commandParser.ParseCommand(line)
+		err := command.Execute(arguments, io.Discard)
+		if err != ErrInvalidArgs {
+			t.Error("Line:", line, "Expected:", ErrInvalidArgs, "Actual:", err)
+		}
+	}
+
+	assertOutputModeAndPrecision(t, resultFormatter, resultformatter.OutputModeReal, -1)
+}
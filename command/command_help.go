@@ -2,12 +2,19 @@ package command
 
 import (
 	"alanmitic/gocalc/expreval"
+	"errors"
 	"fmt"
+	"io"
+	"sort"
 	"strconv"
+	"strings"
 )
 
+var ErrNoHelp = errors.New("no help found")
+
 type CommandHelp struct {
-	commands map[string]Command
+	commands  map[string]Command
+	evaluator *expreval.Evaluator
 }
 
 func (commandHelp *CommandHelp) GetName() string {
@@ -17,25 +24,108 @@ func (commandHelp *CommandHelp) GetName() string {
 func (commandHelp *CommandHelp) GetSignatures() []Signature {
 	return []Signature{
 		// help
-		[]expreval.LexAnToken{}}
+		[]expreval.LexAnToken{},
+		// help <command|topic>
+		[]expreval.LexAnToken{expreval.TokenIdentifier}}
 }
 
-func (commandHelp *CommandHelp) Execute(arguments []Argument) error {
+func (commandHelp *CommandHelp) Execute(arguments []Argument, output io.Writer) error {
+	if len(arguments) == 1 {
+		return commandHelp.printHelp(arguments[0].textValue, output)
+	}
+
 	commands := commandHelp.commands
 
 	longestUsageSyntaxLength := findLongestUsageSyntaxLength(commands)
 
-	fmt.Println("Commands:")
-	for _, command := range commands {
-		usageSyntax, usageDescription := command.GetUsage()
+	fmt.Fprintln(output, "Commands:")
+	for _, name := range getCommandNames(commands) {
+		usageSyntax, usageDescription := commands[name].GetUsage()
 
 		paddedUsageSyntax := fmt.Sprintf("% -"+strconv.Itoa(longestUsageSyntaxLength)+"s", usageSyntax)
-		fmt.Println(paddedUsageSyntax, ":", usageDescription)
+		fmt.Fprintln(output, paddedUsageSyntax, ":", usageDescription)
 	}
 
+	fmt.Fprintln(output)
+	fmt.Fprintln(output, "Topics:", strings.Join(getHelpTopics(), ", "))
+	fmt.Fprintln(output, "Type 'help <command>' or 'help <topic>' for more help.")
+
 	return nil
 }
 
+// Prints the detailed help for a command or topic.
+func (commandHelp *CommandHelp) printHelp(name string, output io.Writer) error {
+	if command, found := commandHelp.commands[name]; found {
+		usageSyntax, usageDescription := command.GetUsage()
+		fmt.Fprintln(output, usageSyntax)
+		fmt.Fprintln(output)
+
+		helpCommand, ok := command.(HelpCommand)
+		if !ok {
+			fmt.Fprintln(output, usageDescription)
+			return nil
+		}
+
+		description, examples := helpCommand.GetHelp()
+		fmt.Fprintln(output, wrapText(description, helpWidth))
+		if len(examples) > 0 {
+			fmt.Fprintln(output)
+			fmt.Fprintln(output, "Examples:")
+			for _, example := range examples {
+				fmt.Fprintln(output, "  "+example)
+			}
+		}
+		return nil
+	}
+
+	if topic, found := helpTopics[name]; found {
+		fmt.Fprintln(output, topic)
+		if name == "functions" {
+			commandHelp.printFunctions(output)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrNoHelp, name)
+}
+
+// Prints the registered functions with their arguments, e.g. "hypot(x1, x2)".
+func (commandHelp *CommandHelp) printFunctions(output io.Writer) {
+	fmt.Fprintln(output)
+	names := commandHelp.evaluator.GetFunctionNames()
+	if len(names) == 0 {
+		fmt.Fprintln(output, "No functions are registered.")
+		return
+	}
+
+	fmt.Fprintln(output, "Functions:")
+	for _, name := range names {
+		arity, _ := commandHelp.evaluator.GetFunctionArity(name)
+		fmt.Fprintln(output, "  "+formatFunctionUsage(name, arity))
+	}
+}
+
+// Formats the usage of a function, e.g. "hypot(x1, x2)", or "sum(...)" for a variadic function.
+func formatFunctionUsage(name string, arity int) string {
+	if arity == expreval.VariadicArity {
+		return name + "(...)"
+	}
+
+	if arity == 1 {
+		return name + "(x)"
+	}
+
+	parameters := []string{}
+	for index := 1; index <= arity; index++ {
+		parameters = append(parameters, "x"+strconv.Itoa(index))
+	}
+	return name + "(" + strings.Join(parameters, ", ") + ")"
+}
+
+func (commandHelp *CommandHelp) GetKeywords() []string {
+	return append(getCommandNames(commandHelp.commands), getHelpTopics()...)
+}
+
 func findLongestUsageSyntaxLength(commands map[string]Command) int {
 	longestUsageLen := 0
 
@@ -51,12 +141,61 @@ func findLongestUsageSyntaxLength(commands map[string]Command) int {
 	return longestUsageLen
 }
 
+// Gets the names of the commands, sorted.
+func getCommandNames(commands map[string]Command) []string {
+	names := []string{}
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Gets the names of the help topics, sorted.
+func getHelpTopics() []string {
+	topics := []string{}
+	for topic := range helpTopics {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// Width that detailed help is wrapped to.
+const helpWidth = 100
+
+// Wraps the text at spaces so that the lines are no longer than the width, where possible.
+func wrapText(text string, width int) string {
+	lines := []string{}
+	line := ""
+	for _, word := range strings.Split(text, " ") {
+		if len(line) > 0 && len(line)+1+len(word) > width {
+			lines = append(lines, strings.TrimRight(line, " "))
+			line = ""
+		}
+
+		if len(line) > 0 {
+			line += " "
+		}
+		line += word
+	}
+
+	return strings.Join(append(lines, line), "\n")
+}
+
 func (commandHelp *CommandHelp) GetUsage() (string, string) {
-	return "help", "Show help"
+	return "help <command|topic>", "Show help, or the detailed help for a command or topic."
+}
+
+func (commandHelp *CommandHelp) GetHelp() (string, []string) {
+	description := "Show the commands, or the detailed help for a command or a topic: " +
+		strings.Join(getHelpTopics(), ", ") + "."
+	return description, []string{"help", "help fix", "help operators"}
 }
 
-func NewCommandHelp(commands map[string]Command) Command {
+func NewCommandHelp(commands map[string]Command, evaluator *expreval.Evaluator) Command {
 	command := CommandHelp{}
 	command.commands = commands
+	command.evaluator = evaluator
 	return &command
 }
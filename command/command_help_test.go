@@ -3,6 +3,9 @@ package command
 import (
 	"alanmitic/gocalc/expreval"
 	"alanmitic/gocalc/resultformatter"
+	"bytes"
+	"errors"
+	"strings"
 	"testing"
 )
 
@@ -13,5 +16,78 @@ func TestCommandHelp(t *testing.T) {
 	command, arguments, _ := commandParser.ParseCommand("help")
 	assertCommand(t, command, "help")
 	assertArguments(t, arguments, []Argument{})
-	// This just outputs to stdout. No specific test.
+
+	output := new(bytes.Buffer)
+	command.Execute(arguments, output)
+	if !strings.HasPrefix(output.String(), "Commands:\n") || !strings.Contains(output.String(), "Show help") {
+		t.Error("Expected:", "Commands:", "Actual:", output.String())
+	}
+}
+
+func TestCommandHelpSorted(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	command, arguments, _ := commandParser.ParseCommand("help")
+
+	output := new(bytes.Buffer)
+	command.Execute(arguments, output)
+	lines := strings.Split(output.String(), "\n")
+	if !strings.HasPrefix(lines[1], "alg ") || !strings.HasPrefix(lines[2], "alias ") {
+		t.Error("Expected:", "alg, alias", "Actual:", lines[1:3])
+	}
+
+	if !strings.Contains(output.String(), "Topics: bases, functions, operators, variables\n") {
+		t.Error("Expected:", "Topics:", "Actual:", output.String())
+	}
+}
+
+func TestCommandHelpCommand(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	command, arguments, _ := commandParser.ParseCommand("help fix")
+	assertArguments(t, arguments, []Argument{{expreval.TokenIdentifier, "fix", 0.0, 0, 0}})
+
+	output := new(bytes.Buffer)
+	err := command.Execute(arguments, output)
+	if err != nil {
+		t.Error("Expected:", nil, "Actual:", err)
+	}
+
+	assertOutput(t, output, "fix <precision>\n\nOutput results in fixed point notation, with the precision as the number "+
+		"of decimal places from 0 to\n17, 2 if it is not given.\n\nExamples:\n  fix\n  fix 4\n  fix $digits + 1\n")
+
+	// Every command has detailed help.
+	for name, command := range commandParser.commands {
+		if _, ok := command.(HelpCommand); !ok {
+			t.Error("No help for command:", name)
+		}
+	}
+}
+
+func TestCommandHelpTopic(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	command, arguments, _ := commandParser.ParseCommand("help operators")
+
+	output := new(bytes.Buffer)
+	command.Execute(arguments, output)
+	if !strings.HasPrefix(output.String(), "Operators,") || !strings.Contains(output.String(), "200 * 15%") {
+		t.Error("Expected:", "Operators,", "Actual:", output.String())
+	}
+
+	command, arguments, _ = commandParser.ParseCommand("help foo")
+	err := command.Execute(arguments, output)
+	if !errors.Is(err, ErrNoHelp) {
+		t.Error("Expected:", ErrNoHelp, "Actual:", err)
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	wrapped := wrapText("one two.  three four", 10)
+	if wrapped != "one two.\nthree four" {
+		t.Errorf("Expected: %q Actual: %q", "one two.\nthree four", wrapped)
+	}
 }
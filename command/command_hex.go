@@ -3,6 +3,7 @@ package command
 import (
 	"alanmitic/gocalc/expreval"
 	"alanmitic/gocalc/resultformatter"
+	"io"
 )
 
 type CommandHex struct {
@@ -19,7 +20,7 @@ func (commandHex *CommandHex) GetSignatures() []Signature {
 		[]expreval.LexAnToken{}}
 }
 
-func (commandHex *CommandHex) Execute(arguments []Argument) error {
+func (commandHex *CommandHex) Execute(arguments []Argument, output io.Writer) error {
 	commandHex.resultFormatter.SetOutputMode(resultformatter.OutputModeHexadecimal)
 	return nil
 }
@@ -28,6 +29,12 @@ func (commandHex *CommandHex) GetUsage() (string, string) {
 	return "hex", "Set hexadecimal output mode."
 }
 
+func (commandHex *CommandHex) GetHelp() (string, []string) {
+	description := "Output results in hexadecimal, as the lower 32 bits of the integer part of the value.  Special " +
+		"values are still output as inf, -inf and nan."
+	return description, []string{"hex", "h$ff + 1"}
+}
+
 func NewCommandHex(resultFormatter resultformatter.ResultFormatter) Command {
 	command := CommandHex{}
 	command.resultFormatter = resultFormatter
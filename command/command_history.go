@@ -0,0 +1,70 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"alanmitic/gocalc/resultformatter"
+	"fmt"
+	"io"
+	"math"
+)
+
+type CommandHistory struct {
+	evaluator       *expreval.Evaluator
+	resultFormatter resultformatter.ResultFormatter
+}
+
+func (commandHistory *CommandHistory) GetName() string {
+	return "history"
+}
+
+func (commandHistory *CommandHistory) GetSignatures() []Signature {
+	return []Signature{
+		// history
+		[]expreval.LexAnToken{},
+		// history <count>
+		[]expreval.LexAnToken{ParameterExpression}}
+}
+
+func (commandHistory *CommandHistory) Execute(arguments []Argument, output io.Writer) error {
+	history := commandHistory.evaluator.History
+	if len(arguments) == 1 {
+		count, err := arguments[0].GetIntegerValue(0, math.MaxInt32)
+		if err != nil {
+			return err
+		}
+
+		if count < len(history) {
+			history = history[len(history)-count:]
+		}
+	}
+
+	if len(commandHistory.evaluator.History) == 0 {
+		fmt.Fprintln(output, "No history!")
+		return nil
+	}
+
+	for _, entry := range history {
+		fmt.Fprintf(output, "[%d] %s => %s\n", entry.Number, entry.Expression,
+			commandHistory.resultFormatter.FormatValue(entry.Result))
+	}
+
+	return nil
+}
+
+func (commandHistory *CommandHistory) GetUsage() (string, string) {
+	return "history <count>", "List the evaluated expressions and their results, optionally only the latest."
+}
+
+func (commandHistory *CommandHistory) GetHelp() (string, []string) {
+	description := "List the evaluated expressions with their result numbers and results, formatted with the current " +
+		"output format.  With a count only the latest results are listed.  Results can be used as $_n or " +
+		"$ans[n], and expressions evaluated again with !n or !!."
+	return description, []string{"history", "history 10"}
+}
+
+func NewCommandHistory(evaluator *expreval.Evaluator, resultFormatter resultformatter.ResultFormatter) Command {
+	command := CommandHistory{}
+	command.evaluator = evaluator
+	command.resultFormatter = resultFormatter
+	return &command
+}
@@ -0,0 +1,74 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"alanmitic/gocalc/resultformatter"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCommandHistory(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+
+	output := new(bytes.Buffer)
+	command, arguments, _ := commandParser.ParseCommand("history")
+	assertCommand(t, command, "history")
+	command.Execute(arguments, output)
+	assertOutput(t, output, "No history!\n")
+
+	evaluator.Evaluate("1 + 2")
+	evaluator.Evaluate("$a = 16")
+	resultFormatter.SetOutputMode(resultformatter.OutputModeHexadecimal)
+
+	output.Reset()
+	command.Execute(arguments, output)
+	assertOutput(t, output, "[1] 1 + 2 => 00000003\n[2] $a = 16 => 00000010\n")
+
+	output.Reset()
+	command, arguments, _ = commandParser.ParseCommand("history 1")
+	command.Execute(arguments, output)
+	assertOutput(t, output, "[2] $a = 16 => 00000010\n")
+
+	for _, line := range []string{"history -1", "history 1.5"} {
+		command, arguments, _ = commandParser.ParseCommand(line)
+		err := command.Execute(arguments, output)
+		if err != ErrInvalidArgs {
+			t.Error("Line:", line, "Expected:", ErrInvalidArgs, "Actual:", err)
+		}
+	}
+}
+
+func TestScriptRunnerRecall(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	commandParser := NewCommandParser(evaluator, resultformatter.NewResultFormatter())
+	scriptRunner := commandParser.GetScriptRunner()
+	output := new(bytes.Buffer)
+	scriptRunner.Output = output
+	scriptRunner.NumberResults = true
+
+	scriptRunner.ExecuteLine("$a = 2")
+	scriptRunner.ExecuteLine("$a * 10")
+	scriptRunner.ExecuteLine("$a = 3")
+	scriptRunner.ExecuteLine("!2")
+	scriptRunner.ExecuteLine(" !! ")
+	assertOutput(t, output, "[1] 2\n[2] 20\n[3] 3\n$a * 10\n[4] 30\n$a * 10\n[5] 30\n")
+
+	err := scriptRunner.ExecuteLine("!9")
+	if !errors.Is(err, expreval.ErrHistoryNotFound) {
+		t.Error("Expected:", expreval.ErrHistoryNotFound, "Actual:", err)
+	}
+
+	// The position of an error is shown in the recalled line.
+	scriptRunner.ExecuteLine("1 / $a")
+	evaluator.Variables.SetVariable("$a", 0)
+	err = scriptRunner.ExecuteLine("!-1")
+	output.Reset()
+	PrintError(output, err, "!-1")
+	if !strings.HasSuffix(output.String(), "  1 / $a\n    ^\n") {
+		t.Error("Expected:", "caret in recalled line", "Actual:", output.String())
+	}
+}
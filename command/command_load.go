@@ -0,0 +1,48 @@
+package command
+
+import (
+	"io"
+)
+
+type CommandLoad struct {
+	commandParser *CommandParser
+}
+
+func (commandLoad *CommandLoad) GetName() string {
+	return "load"
+}
+
+func (commandLoad *CommandLoad) GetSignatures() []Signature {
+	return []Signature{}
+}
+
+func (commandLoad *CommandLoad) IsTextRequired() bool {
+	// load [file]
+	return false
+}
+
+func (commandLoad *CommandLoad) Execute(arguments []Argument, output io.Writer) error {
+	fileName, err := getStateFileName(arguments)
+	if err != nil {
+		return err
+	}
+
+	return LoadStateFile(fileName, commandLoad.commandParser)
+}
+
+func (commandLoad *CommandLoad) GetUsage() (string, string) {
+	return "load <file>", "Load saved variables, settings, macros and aliases, by default from the state file."
+}
+
+func (commandLoad *CommandLoad) GetHelp() (string, []string) {
+	description := "Load the variables, output format, settings, macros and aliases saved by save, from the file " +
+		"given or otherwise the state file in the user configuration directory.  Variables, macros and " +
+		"aliases that are not in the file are kept."
+	return description, []string{"load", "load rates.json"}
+}
+
+func NewCommandLoad(commandParser *CommandParser) Command {
+	command := CommandLoad{}
+	command.commandParser = commandParser
+	return &command
+}
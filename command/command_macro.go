@@ -0,0 +1,74 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"fmt"
+	"io"
+	"sort"
+)
+
+type CommandMacro struct {
+	scriptRunner *ScriptRunner
+}
+
+func (commandMacro *CommandMacro) GetName() string {
+	return "macro"
+}
+
+func (commandMacro *CommandMacro) GetSignatures() []Signature {
+	return []Signature{
+		// macro
+		[]expreval.LexAnToken{},
+		// macro <name>
+		[]expreval.LexAnToken{expreval.TokenIdentifier}}
+}
+
+func (commandMacro *CommandMacro) Execute(arguments []Argument, output io.Writer) error {
+	if len(arguments) == 1 {
+		return commandMacro.scriptRunner.startMacro(arguments[0].textValue)
+	}
+
+	macros := []*Macro{}
+	for _, command := range commandMacro.scriptRunner.commandParser.commands {
+		if macro, isMacro := command.(*Macro); isMacro {
+			macros = append(macros, macro)
+		}
+	}
+
+	if len(macros) == 0 {
+		fmt.Fprintln(output, "No macros defined!")
+		return nil
+	}
+
+	sort.Slice(macros, func(i int, j int) bool {
+		return macros[i].name < macros[j].name
+	})
+
+	for _, macro := range macros {
+		fmt.Fprintln(output, "macro", macro.name)
+		for _, line := range macro.lines {
+			fmt.Fprintln(output, "  "+line)
+		}
+		fmt.Fprintln(output, "end")
+	}
+
+	return nil
+}
+
+func (commandMacro *CommandMacro) GetUsage() (string, string) {
+	return "macro <name>", "Record the following lines up to end as a macro run by name, or list the macros."
+}
+
+func (commandMacro *CommandMacro) GetHelp() (string, []string) {
+	description := "Record the following command and expression lines, up to a line of end, as a macro that runs " +
+		"them when its name is used as a command.  $1, $2 and so on in the lines are replaced by the " +
+		"comma separated arguments given to the macro.  A macro stops at the first line with an error.  " +
+		"With no name the macros are listed."
+	return description, []string{"macro area", "$1 * $2", "end", "area 3, 4"}
+}
+
+func NewCommandMacro(scriptRunner *ScriptRunner) Command {
+	command := CommandMacro{}
+	command.scriptRunner = scriptRunner
+	return &command
+}
@@ -0,0 +1,129 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"alanmitic/gocalc/resultformatter"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCommandMacro(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	scriptRunner := commandParser.GetScriptRunner()
+	output := new(bytes.Buffer)
+	scriptRunner.Output = output
+
+	for _, line := range []string{"macro area", "fix 1", "$area = $1 * $2", "end"} {
+		err := scriptRunner.ExecuteLine(line)
+		if err != nil {
+			t.Fatal("Line:", line, "Expected:", nil, "Actual:", err)
+		}
+	}
+
+	if output.Len() != 0 || scriptRunner.IsRecordingMacro() {
+		t.Error("Expected:", "lines recorded", "Actual:", output.String())
+	}
+
+	err := scriptRunner.ExecuteLine("area 3, 1 + 1")
+	if err != nil {
+		t.Error("Expected:", nil, "Actual:", err)
+	}
+	assertOutput(t, output, "6.0\n")
+	assertScriptVariable(t, evaluator, "$area", 6)
+
+	// The results are written to the output given to the command.
+	output.Reset()
+	command, arguments, _ := commandParser.ParseCommand("area 2, 2")
+	commandOutput := new(bytes.Buffer)
+	command.Execute(arguments, commandOutput)
+	assertOutput(t, commandOutput, "4.0\n")
+	assertOutput(t, output, "")
+
+	command, _, err = commandParser.ParseCommand("area 3")
+	assertNilCommandAndError(t, command, err, ErrInvalidArgs)
+	if err.Error() != "command arguments are invalid, usage: area <$1>, <$2>" {
+		t.Error("Expected:", "usage: area <$1>, <$2>", "Actual:", err)
+	}
+
+	// Macros are listed, and can be redefined but not named after a command.
+	output.Reset()
+	scriptRunner.ExecuteLine("macro")
+	assertOutput(t, output, "macro area\n  fix 1\n  $area = $1 * $2\nend\n")
+
+	scriptRunner.ExecuteLine("macro area")
+	scriptRunner.ExecuteLine("end")
+	command, arguments, _ = commandParser.ParseCommand("area")
+	assertArguments(t, arguments, []Argument{})
+	assertCommand(t, command, "area")
+
+	err = scriptRunner.ExecuteLine("macro fix")
+	if !errors.Is(err, ErrNameConflict) || scriptRunner.IsRecordingMacro() {
+		t.Error("Expected:", ErrNameConflict, "Actual:", err)
+	}
+}
+
+func TestCommandMacroErrors(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	scriptRunner := commandParser.GetScriptRunner()
+	errorOutput := new(bytes.Buffer)
+	scriptRunner.ErrorOutput = errorOutput
+	scriptRunner.Output = io.Discard
+
+	// A macro stops at the first line that fails, and the error is shown in the line.
+	script := "macro bad\n$a = 1\n$b = $1 + $nope\n$c = 3\nend\nbad 2\n"
+	err := scriptRunner.Run(strings.NewReader(script), "test.gc")
+	if err != ErrScriptFailed {
+		t.Error("Expected:", ErrScriptFailed, "Actual:", err)
+	}
+
+	expectedError := "ERROR: test.gc:6: bad:2: undefined variable $nope at column 12\n" +
+		"  $b = (2) + $nope\n" +
+		"             ^\n"
+	if errorOutput.String() != expectedError {
+		t.Error("Expected:", expectedError, "Actual:", errorOutput.String())
+	}
+
+	if _, found := evaluator.Variables.GetVariable("$c"); found {
+		t.Error("Variable should not be defined: $c")
+	}
+
+	// The error is shown in the line of the innermost macro.
+	errorOutput.Reset()
+	script = "macro inner\n1 + 2 + 3 + 4 + $undefinedvar\nend\nmacro outer\ninner\nend\nouter\n"
+	scriptRunner.Run(strings.NewReader(script), "test.gc")
+	expectedError = "ERROR: test.gc:7: outer:1: inner:1: undefined variable $undefinedvar at column 17\n" +
+		"  1 + 2 + 3 + 4 + $undefinedvar\n" +
+		"                  ^\n"
+	if errorOutput.String() != expectedError {
+		t.Error("Expected:", expectedError, "Actual:", errorOutput.String())
+	}
+
+	// A macro must end in the script that starts it.
+	errorOutput.Reset()
+	err = scriptRunner.Run(strings.NewReader("macro open\n1 + 1\n"), "test.gc")
+	if err != ErrScriptFailed || scriptRunner.IsRecordingMacro() {
+		t.Error("Expected:", ErrScriptFailed, "Actual:", err)
+	}
+	assertOutput(t, errorOutput, "ERROR: test.gc:2: macro has no end: open\n")
+
+	scriptRunner.ExecuteLine("macro outer")
+	err = scriptRunner.ExecuteLine("macro inner")
+	if err != ErrNestedMacro {
+		t.Error("Expected:", ErrNestedMacro, "Actual:", err)
+	}
+
+	// A macro that runs itself is stopped.
+	scriptRunner.ExecuteLine("outer")
+	scriptRunner.ExecuteLine("end")
+	err = scriptRunner.ExecuteLine("outer")
+	if !errors.Is(err, ErrSourceDepth) {
+		t.Error("Expected:", ErrSourceDepth, "Actual:", err)
+	}
+}
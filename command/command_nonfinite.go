@@ -0,0 +1,66 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"fmt"
+	"io"
+)
+
+type CommandNonFinite struct {
+	evaluator *expreval.Evaluator
+}
+
+func (commandNonFinite *CommandNonFinite) GetName() string {
+	return "nonfinite"
+}
+
+func (commandNonFinite *CommandNonFinite) GetSignatures() []Signature {
+	return []Signature{
+		// nonfinite
+		[]expreval.LexAnToken{},
+		// nonfinite <error|propagate>
+		[]expreval.LexAnToken{expreval.TokenIdentifier}}
+}
+
+func (commandNonFinite *CommandNonFinite) Execute(arguments []Argument, output io.Writer) error {
+	if len(arguments) == 0 {
+		if commandNonFinite.evaluator.NonFinitePolicy == expreval.NonFinitePropagate {
+			fmt.Fprintln(output, "nonfinite propagate")
+		} else {
+			fmt.Fprintln(output, "nonfinite error")
+		}
+		return nil
+	}
+
+	switch arguments[0].textValue {
+	case "error":
+		commandNonFinite.evaluator.NonFinitePolicy = expreval.NonFiniteError
+	case "propagate":
+		commandNonFinite.evaluator.NonFinitePolicy = expreval.NonFinitePropagate
+	default:
+		return ErrInvalidArgs
+	}
+
+	return nil
+}
+
+func (commandNonFinite *CommandNonFinite) GetKeywords() []string {
+	return []string{"error", "propagate"}
+}
+
+func (commandNonFinite *CommandNonFinite) GetUsage() (string, string) {
+	return "nonfinite <error|propagate>", "Set or show whether overflow and NaN results are errors."
+}
+
+func (commandNonFinite *CommandNonFinite) GetHelp() (string, []string) {
+	description := "Set whether an operation on finite values that overflows, such as 10 ^ 400, or has an undefined " +
+		"result is an error, or gives inf, -inf or nan as the result.  With no argument the current " +
+		"setting is shown."
+	return description, []string{"nonfinite", "nonfinite propagate"}
+}
+
+func NewCommandNonFinite(evaluator *expreval.Evaluator) Command {
+	command := CommandNonFinite{}
+	command.evaluator = evaluator
+	return &command
+}
@@ -0,0 +1,36 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"alanmitic/gocalc/resultformatter"
+	"io"
+	"testing"
+)
+
+func TestCommandNonFinite(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	command, arguments, _ := commandParser.ParseCommand("nonfinite propagate")
+	assertCommand(t, command, "nonfinite")
+	assertArguments(t, arguments, []Argument{{expreval.TokenIdentifier, "propagate", 0.0, 0, 0}})
+
+	command.Execute(arguments, io.Discard)
+	if evaluator.NonFinitePolicy != expreval.NonFinitePropagate {
+		t.Error("Expected:", expreval.NonFinitePropagate, "Actual:", evaluator.NonFinitePolicy)
+	}
+
+	command, arguments, _ = commandParser.ParseCommand("nonfinite error")
+	command.Execute(arguments, io.Discard)
+	if evaluator.NonFinitePolicy != expreval.NonFiniteError {
+		t.Error("Expected:", expreval.NonFiniteError, "Actual:", evaluator.NonFinitePolicy)
+	}
+}
+
+func TestCommandParserLiteralIsNotCommand(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	command, _, err := commandParser.ParseCommand("inf - 1")
+	assertNilCommandAndError(t, command, err, nil)
+}
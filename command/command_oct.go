@@ -3,6 +3,7 @@ package command
 import (
 	"alanmitic/gocalc/expreval"
 	"alanmitic/gocalc/resultformatter"
+	"io"
 )
 
 type CommandOct struct {
@@ -19,7 +20,7 @@ func (commandOct *CommandOct) GetSignatures() []Signature {
 		[]expreval.LexAnToken{}}
 }
 
-func (commandOct *CommandOct) Execute(arguments []Argument) error {
+func (commandOct *CommandOct) Execute(arguments []Argument, output io.Writer) error {
 	commandOct.resultFormatter.SetOutputMode(resultformatter.OutputModeOctal)
 	return nil
 }
@@ -28,6 +29,12 @@ func (commandOct *CommandOct) GetUsage() (string, string) {
 	return "oct", "Set octal output mode."
 }
 
+func (commandOct *CommandOct) GetHelp() (string, []string) {
+	description := "Output results in octal, as the lower 32 bits of the integer part of the value.  Special values " +
+		"are still output as inf, -inf and nan."
+	return description, []string{"oct", "o$777 + 1"}
+}
+
 func NewCommandOct(resultFormatter resultformatter.ResultFormatter) Command {
 	command := CommandOct{}
 	command.resultFormatter = resultFormatter
@@ -3,6 +3,7 @@ package command
 import (
 	"alanmitic/gocalc/expreval"
 	"alanmitic/gocalc/resultformatter"
+	"io"
 	"testing"
 )
 
@@ -14,7 +15,7 @@ func TestCommandOct(t *testing.T) {
 	assertCommand(t, command, "oct")
 	assertArguments(t, arguments, []Argument{})
 
-	command.Execute(arguments)
+	command.Execute(arguments, io.Discard)
 	assertOutputModeAndPrecision(t, resultFormatter, resultformatter.OutputModeOctal, -1)
 }
 
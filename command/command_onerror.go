@@ -0,0 +1,65 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"fmt"
+	"io"
+)
+
+type CommandOnError struct {
+	scriptRunner *ScriptRunner
+}
+
+func (commandOnError *CommandOnError) GetName() string {
+	return "onerror"
+}
+
+func (commandOnError *CommandOnError) GetSignatures() []Signature {
+	return []Signature{
+		// onerror
+		[]expreval.LexAnToken{},
+		// onerror <stop|continue>
+		[]expreval.LexAnToken{expreval.TokenIdentifier}}
+}
+
+func (commandOnError *CommandOnError) Execute(arguments []Argument, output io.Writer) error {
+	if len(arguments) == 0 {
+		if commandOnError.scriptRunner.StopOnError {
+			fmt.Fprintln(output, "onerror stop")
+		} else {
+			fmt.Fprintln(output, "onerror continue")
+		}
+		return nil
+	}
+
+	switch arguments[0].textValue {
+	case "stop":
+		commandOnError.scriptRunner.StopOnError = true
+	case "continue":
+		commandOnError.scriptRunner.StopOnError = false
+	default:
+		return ErrInvalidArgs
+	}
+
+	return nil
+}
+
+func (commandOnError *CommandOnError) GetKeywords() []string {
+	return []string{"stop", "continue"}
+}
+
+func (commandOnError *CommandOnError) GetUsage() (string, string) {
+	return "onerror <stop|continue>", "Set or show whether scripts stop at the first error."
+}
+
+func (commandOnError *CommandOnError) GetHelp() (string, []string) {
+	description := "Set whether a script stops at the first line with an error, or reports it and continues with the " +
+		"next line, the default.  With no argument the current setting is shown."
+	return description, []string{"onerror", "onerror stop"}
+}
+
+func NewCommandOnError(scriptRunner *ScriptRunner) Command {
+	command := CommandOnError{}
+	command.scriptRunner = scriptRunner
+	return &command
+}
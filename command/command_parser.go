@@ -4,21 +4,36 @@ import (
 	"alanmitic/gocalc/expreval"
 	"alanmitic/gocalc/resultformatter"
 	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 var ErrGeneral = errors.New("general error parsing command")
 var ErrTooManyArgs = errors.New("too many arguments")
 var ErrInvalidArgs = errors.New("command arguments are invalid")
 var ErrNotFound = errors.New("command not found")
+var ErrAmbiguousCommand = errors.New("ambiguous command")
+var ErrNameConflict = errors.New("name is already used")
 
 type CommandParser struct {
 	commands map[string]Command
+	// Names that are replaced by the start of a command line, e.g. "h" by "hex".
+	aliases         map[string]string
+	evaluator       *expreval.Evaluator
+	resultFormatter resultformatter.ResultFormatter
+	scriptRunner    *ScriptRunner
 }
 
 func NewCommandParser(evaluator *expreval.Evaluator, resultformatter resultformatter.ResultFormatter) *CommandParser {
 	commandParser := CommandParser{}
 
 	commandParser.commands = make(map[string]Command)
+	commandParser.aliases = make(map[string]string)
+	commandParser.evaluator = evaluator
+	commandParser.resultFormatter = resultformatter
 
 	addCommand(commandParser.commands, NewCommandExit())
 	addCommand(commandParser.commands, NewCommandFix(resultformatter))
@@ -27,40 +42,251 @@ func NewCommandParser(evaluator *expreval.Evaluator, resultformatter resultforma
 	addCommand(commandParser.commands, NewCommandBin(resultformatter))
 	addCommand(commandParser.commands, NewCommandOct(resultformatter))
 	addCommand(commandParser.commands, NewCommandHex(resultformatter))
-	addCommand(commandParser.commands, NewCommandVars(evaluator))
-	addCommand(commandParser.commands, NewCommandHelp(commandParser.commands))
+	addCommand(commandParser.commands, NewCommandVars(evaluator, resultformatter))
+	addCommand(commandParser.commands, NewCommandUnset(evaluator))
+	addCommand(commandParser.commands, NewCommandClear(evaluator))
+	addCommand(commandParser.commands, NewCommandRename(evaluator))
+	addCommand(commandParser.commands, NewCommandConst(evaluator))
+	addCommand(commandParser.commands, NewCommandHistory(evaluator, resultformatter))
+	addCommand(commandParser.commands, NewCommandStrict(evaluator))
+	addCommand(commandParser.commands, NewCommandNonFinite(evaluator))
+	addCommand(commandParser.commands, NewCommandContinue(evaluator))
+	addCommand(commandParser.commands, NewCommandPlot(evaluator, resultformatter))
+	addCommand(commandParser.commands, NewCommandTable(evaluator, resultformatter))
+	addCommand(commandParser.commands, NewCommandSave(&commandParser))
+	addCommand(commandParser.commands, NewCommandLoad(&commandParser))
+	addCommand(commandParser.commands, NewCommandHelp(commandParser.commands, evaluator))
+
+	commandParser.scriptRunner = NewScriptRunner(&commandParser, evaluator, resultformatter)
+	addCommand(commandParser.commands, NewCommandSource(commandParser.scriptRunner))
+	addCommand(commandParser.commands, NewCommandOnError(commandParser.scriptRunner))
+	addCommand(commandParser.commands, NewCommandRPN(commandParser.scriptRunner))
+	addCommand(commandParser.commands, NewCommandAlg(commandParser.scriptRunner))
+	addCommand(commandParser.commands, NewCommandPrompt(commandParser.scriptRunner))
+	addCommand(commandParser.commands, NewCommandMacro(commandParser.scriptRunner))
+	addCommand(commandParser.commands, NewCommandAlias(&commandParser))
+	addCommand(commandParser.commands, NewCommandUnalias(&commandParser))
 
 	return &commandParser
 }
 
+// Gets the script runner that executes lines through this command parser.
+func (commandParser *CommandParser) GetScriptRunner() *ScriptRunner {
+	return commandParser.scriptRunner
+}
+
 func addCommand(commands map[string]Command, command Command) {
 	commands[command.GetName()] = command
 }
 
 func (commandParser *CommandParser) ParseCommand(input string) (Command, []Argument, error) {
-	var command Command = nil
 	lexAn := expreval.CreateLexicalAnalyser(input)
 
-	// Get first token and it needs to be an identifier for it to be a command.
+	// Get first token and it needs to be an identifier, other than a literal or function, for it to be a command.
 	token := lexAn.ParseNextToken()
-	if token == expreval.TokenIdentifier {
+	if token == expreval.TokenIdentifier && !expreval.IsLiteral(lexAn.GetTextValue()) &&
+		!commandParser.evaluator.IsFunction(lexAn.GetTextValue()) {
+		// An alias is replaced by its command line, e.g. "h" by "hex".
+		if expandedInput, expanded := commandParser.expandAlias(input); expanded {
+			input = expandedInput
+			lexAn = expreval.CreateLexicalAnalyser(input)
+			lexAn.ParseNextToken()
+		}
+
 		// Find the command.
-		command = commandParser.commands[lexAn.GetTextValue()]
-		if command != nil {
-			arguments, err := parseArguments(lexAn, command)
-			if err != nil {
-				return nil, nil, err
-			}
-			return command, arguments, nil
-		} else {
-			return nil, nil, ErrNotFound
+		command, err := commandParser.findCommand(lexAn.GetTextValue())
+		if err != nil {
+			return nil, nil, err
+		}
+
+		arguments, err := commandParser.parseCommandArguments(input, lexAn, command)
+		if err != nil {
+			return nil, nil, newUsageError(command, err)
 		}
+		return command, arguments, nil
 	}
 
 	return nil, nil, nil
 }
 
-func parseArguments(lexAn expreval.LexicalAnalyser, command Command) ([]Argument, error) {
+// Replaces an alias at the start of the input by its command line, e.g. "h 2" by "hex 2", reporting whether it was
+// replaced.
+func (commandParser *CommandParser) expandAlias(input string) (string, bool) {
+	lexAn := expreval.CreateLexicalAnalyser(input)
+	if lexAn.ParseNextToken() != expreval.TokenIdentifier {
+		return input, false
+	}
+
+	aliasText, found := commandParser.aliases[lexAn.GetTextValue()]
+	if !found {
+		return input, false
+	}
+
+	return aliasText + " " + lexAn.GetRemainingInput(), true
+}
+
+// Commands that end the session or remove state, which are only found by their full name so that they are not run by
+// mistake, e.g. by a line "e".
+var fullNameCommands = map[string]bool{"exit": true, "clear": true}
+
+// Finds the command with the name, or otherwise the only command whose name starts with it, e.g. "sc" for "sci".
+func (commandParser *CommandParser) findCommand(name string) (Command, error) {
+	if command, found := commandParser.commands[name]; found {
+		return command, nil
+	}
+
+	matches := []string{}
+	for _, commandName := range getCommandNames(commandParser.commands) {
+		if strings.HasPrefix(commandName, name) && !fullNameCommands[commandName] {
+			matches = append(matches, commandName)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, ErrNotFound
+	case 1:
+		return commandParser.commands[matches[0]], nil
+	default:
+		return nil, fmt.Errorf("%w: %s could be %s", ErrAmbiguousCommand, name, strings.Join(matches, ", "))
+	}
+}
+
+// Finds the command that a line starting with the name runs, either through an alias or by its name or prefix.
+func (commandParser *CommandParser) lookupCommand(name string) (Command, error) {
+	if aliasText, found := commandParser.aliases[name]; found {
+		name = strings.Fields(aliasText)[0]
+	}
+	return commandParser.findCommand(name)
+}
+
+// Adds an alias that replaces the name by the text at the start of a command line, replacing any alias with the same
+// name.  The text must start with the name of a command, and the name must not already be a command or macro.
+func (commandParser *CommandParser) AddAlias(name string, text string) error {
+	if err := commandParser.checkName(name); err != nil {
+		return err
+	}
+
+	if _, found := commandParser.commands[name]; found {
+		return fmt.Errorf("%w: %s is a macro", ErrNameConflict, name)
+	}
+
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return ErrInvalidArgs
+	}
+
+	if _, found := commandParser.commands[fields[0]]; !found {
+		return fmt.Errorf("%w: %s", ErrNotFound, fields[0])
+	}
+
+	commandParser.aliases[name] = strings.Join(fields, " ")
+	return nil
+}
+
+// Removes an alias.
+func (commandParser *CommandParser) RemoveAlias(name string) error {
+	if _, found := commandParser.aliases[name]; !found {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	delete(commandParser.aliases, name)
+	return nil
+}
+
+// Gets the aliases, by name.
+func (commandParser *CommandParser) GetAliases() map[string]string {
+	aliases := make(map[string]string)
+	for name, text := range commandParser.aliases {
+		aliases[name] = text
+	}
+	return aliases
+}
+
+// Checks that a name for an alias, macro or command is an identifier that is not a literal, a function or a command
+// other than a macro.
+func (commandParser *CommandParser) checkName(name string) error {
+	lexAn := expreval.CreateLexicalAnalyser(name)
+	if lexAn.ParseNextToken() != expreval.TokenIdentifier || lexAn.GetTextValue() != name || expreval.IsLiteral(name) {
+		return ErrInvalidArgs
+	}
+
+	if commandParser.evaluator.IsFunction(name) {
+		return fmt.Errorf("%w: %s is a function", ErrNameConflict, name)
+	}
+
+	if command, found := commandParser.commands[name]; found {
+		if _, isMacro := command.(*Macro); !isMacro {
+			return fmt.Errorf("%w: %s is a command", ErrNameConflict, name)
+		}
+	}
+
+	return nil
+}
+
+// Registers a command, e.g. one for an application embedding the calculator, so that it can be run, completed and
+// listed by help.  The name must not already be used by a literal, function, command, alias or macro.
+func (commandParser *CommandParser) Register(command Command) error {
+	name := command.GetName()
+	if err := commandParser.checkName(name); err != nil {
+		return err
+	}
+
+	if _, found := commandParser.commands[name]; found {
+		return fmt.Errorf("%w: %s is a macro", ErrNameConflict, name)
+	}
+
+	if _, found := commandParser.aliases[name]; found {
+		return fmt.Errorf("%w: %s is an alias", ErrNameConflict, name)
+	}
+
+	addCommand(commandParser.commands, command)
+	return nil
+}
+
+// Registers a function with the evaluator, as Evaluator.RegisterFunction does, so that it can be called in expressions.
+// The name must not already be used by a command, alias or macro, as a line starting with it would not be evaluated.
+func (commandParser *CommandParser) RegisterFunction(name string, arity int, function expreval.Function) error {
+	if _, found := commandParser.commands[name]; found {
+		return fmt.Errorf("%w: %s is a command", ErrNameConflict, name)
+	}
+
+	if _, found := commandParser.aliases[name]; found {
+		return fmt.Errorf("%w: %s is an alias", ErrNameConflict, name)
+	}
+
+	return commandParser.evaluator.RegisterFunction(name, arity, function)
+}
+
+// Adds a macro, replacing any macro with the same name.
+func (commandParser *CommandParser) addMacro(macro *Macro) error {
+	if err := commandParser.checkName(macro.name); err != nil {
+		return err
+	}
+
+	if _, found := commandParser.aliases[macro.name]; found {
+		return fmt.Errorf("%w: %s is an alias", ErrNameConflict, macro.name)
+	}
+
+	addCommand(commandParser.commands, macro)
+	return nil
+}
+
+func (commandParser *CommandParser) parseCommandArguments(input string, lexAn expreval.LexicalAnalyser,
+	command Command) ([]Argument, error) {
+	if textCommand, ok := command.(TextCommand); ok {
+		return parseText(input, lexAn, textCommand)
+	}
+
+	if expressionListCommand, ok := command.(ExpressionListCommand); ok {
+		return parseExpressionList(input, lexAn, expressionListCommand)
+	}
+
+	return commandParser.parseArguments(input, lexAn, command)
+}
+
+func (commandParser *CommandParser) parseArguments(input string, lexAn expreval.LexicalAnalyser,
+	command Command) ([]Argument, error) {
 	signatures := command.GetSignatures()
 	maxNumArgs := 0
 	for _, signature := range signatures {
@@ -70,64 +296,224 @@ func parseArguments(lexAn expreval.LexicalAnalyser, command Command) ([]Argument
 		}
 	}
 
+	variadic := false
+	if variadicCommand, ok := command.(VariadicCommand); ok {
+		variadic = variadicCommand.IsVariadic()
+	}
+
 	// Slurp the rest of the tokens as arguments.
-	arguments, err := slurpArguments(lexAn, maxNumArgs)
+	arguments, offsets, err := slurpArguments(lexAn)
 	if err != nil {
 		return nil, err
 	}
 
 	// Find the matching signature.
-	findMatchingSignature(signatures, arguments)
+	_, err = findMatchingSignature(signatures, arguments, variadic)
+	if err == nil {
+		return arguments, nil
+	}
 
-	// If no signature is found return an error.
+	// Otherwise the arguments may end with an expression, e.g. "fix $digits".
+	for _, signature := range signatures {
+		last := len(signature) - 1
+		if last < 0 || signature[last] != ParameterExpression || len(arguments) <= last {
+			continue
+		}
 
-	return arguments, nil
+		if _, err := findMatchingSignature([]Signature{signature[:last]}, arguments[:last], false); err != nil {
+			continue
+		}
+
+		argument, err := commandParser.evaluateArgument(input, offsets[last])
+		if errors.Is(err, expreval.ErrSyntax) && !variadic && hasExtraArguments(signatures, arguments) {
+			// E.g. "fix 2 5", where the arguments are complete before the end of the expression.
+			return nil, ErrTooManyArgs
+		}
+
+		if err != nil {
+			usageSyntax, _ := command.GetUsage()
+			return nil, &UsageError{usageSyntax, err}
+		}
+
+		return append(arguments[:last], argument), nil
+	}
+
+	if len(arguments) > maxNumArgs && !variadic {
+		return nil, ErrTooManyArgs
+	}
+	return nil, err
+}
+
+// Evaluates the input from the offset as an expression argument.  The evaluation does not change $ans or the history,
+// and the position of any error is within the input.
+func (commandParser *CommandParser) evaluateArgument(input string, offset int) (Argument, error) {
+	expression := strings.TrimSpace(input[offset:])
+
+	restore := preserveEvaluatorState(commandParser.evaluator, "$ans")
+	value, err := commandParser.evaluator.Evaluate(expression)
+	restore()
+
+	var evaluationError *expreval.EvaluationError
+	if errors.As(err, &evaluationError) {
+		evaluationError.Start += offset
+		evaluationError.End += offset
+		evaluationError.Column = utf8.RuneCountInString(input[:evaluationError.Start]) + 1
+	}
+
+	if err != nil {
+		return Argument{}, err
+	}
+
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return Argument{}, ErrInvalidArgs
+	}
+
+	return Argument{expreval.TokenNumber, expression, value, 0, 0}, nil
 }
 
-func slurpArguments(lexAn expreval.LexicalAnalyser, maxNumArgs int) ([]Argument, error) {
-	//arguments := make([]Argument, maxNumArgs)
+func parseExpressionList(input string, lexAn expreval.LexicalAnalyser, command ExpressionListCommand) ([]Argument,
+	error) {
 	arguments := []Argument{}
-	numArgs := 0
+	if len(strings.TrimSpace(lexAn.GetRemainingInput())) == 0 && command.GetMinExpressions() == 0 {
+		return arguments, nil
+	}
 
-	for {
-		token := lexAn.ParseNextToken()
+	remainingOffset := len(input) - len(lexAn.GetRemainingInput())
+	expressions, offsets := splitExpressionList(lexAn.GetRemainingInput())
+	for index, expression := range expressions {
+		if len(expression) == 0 {
+			return nil, ErrInvalidArgs
+		}
 
-		if token == expreval.TokenBad {
-			return nil, ErrGeneral
+		arguments = append(arguments, newTextArgument(input, expression, remainingOffset+offsets[index]))
+	}
+
+	if len(arguments) < command.GetMinExpressions() {
+		return nil, ErrInvalidArgs
+	}
+
+	return arguments, nil
+}
+
+func parseText(input string, lexAn expreval.LexicalAnalyser, command TextCommand) ([]Argument, error) {
+	remainingInput := lexAn.GetRemainingInput()
+	text := strings.TrimSpace(remainingInput)
+	if len(text) == 0 {
+		if command.IsTextRequired() {
+			return nil, ErrInvalidArgs
 		}
+		return []Argument{}, nil
+	}
+
+	leadingSpace := len(remainingInput) - len(strings.TrimLeftFunc(remainingInput, unicode.IsSpace))
+	return []Argument{newTextArgument(input, text, len(input)-len(remainingInput)+leadingSpace)}, nil
+}
 
-		if token == expreval.TokenEnd {
-			break
+// Creates an argument for the text found at the offset in the input.
+func newTextArgument(input string, text string, offset int) Argument {
+	return Argument{expreval.TokenEnd, text, 0, offset, utf8.RuneCountInString(input[:offset])}
+}
+
+// Splits the input at the commas that are not enclosed by parentheses, returning the expressions with the offset of
+// each in the input.
+func splitExpressionList(input string) ([]string, []int) {
+	expressions := []string{}
+	offsets := []int{}
+	parenthesesLevel := 0
+	start := 0
+
+	addExpression := func(end int) {
+		text := input[start:end]
+		expressions = append(expressions, strings.TrimSpace(text))
+		offsets = append(offsets, start+len(text)-len(strings.TrimLeftFunc(text, unicode.IsSpace)))
+	}
+
+	for index, c := range input {
+		switch c {
+		case '(':
+			parenthesesLevel++
+		case ')':
+			parenthesesLevel--
+		case ',':
+			if parenthesesLevel == 0 {
+				addExpression(index)
+				start = index + 1
+			}
 		}
+	}
 
-		numArgs++
-		if numArgs > maxNumArgs {
-			return nil, ErrTooManyArgs
+	addExpression(len(input))
+	return expressions, offsets
+}
+
+// Gets the remaining tokens as arguments, with the offset of each argument in the input.
+func slurpArguments(lexAn expreval.LexicalAnalyser) ([]Argument, []int, error) {
+	arguments := []Argument{}
+	offsets := []int{}
+
+	token := lexAn.ParseNextToken()
+	for token != expreval.TokenEnd {
+		if token == expreval.TokenBad {
+			return nil, nil, ErrGeneral
+		}
+
+		offset, _ := lexAn.GetTokenSpan()
+		offsets = append(offsets, offset)
+
+		// A minus before a number is a negative number, e.g. "exit -1".
+		if token == expreval.TokenOpMinus {
+			token = lexAn.ParseNextToken()
+			if token == expreval.TokenNumber {
+				arguments = append(arguments, Argument{expreval.TokenNumber, "", -lexAn.GetNumericValue(), 0, 0})
+				token = lexAn.ParseNextToken()
+			} else {
+				arguments = append(arguments, Argument{expreval.TokenOpMinus, "", 0.0, 0, 0})
+			}
+			continue
 		}
 
-		arguments = append(arguments, Argument{token, lexAn.GetTextValue(), lexAn.GetNumericValue()})
+		arguments = append(arguments, Argument{token, lexAn.GetTextValue(), lexAn.GetNumericValue(), 0, 0})
+		token = lexAn.ParseNextToken()
 	}
 
-	return arguments, nil
+	return arguments, offsets, nil
 }
 
-func findMatchingSignature(signatures []Signature, arguments []Argument) (Signature, error) {
+// Reports whether the arguments start with arguments matching a signature, followed by further arguments.
+func hasExtraArguments(signatures []Signature, arguments []Argument) bool {
+	for _, signature := range signatures {
+		if len(arguments) > len(signature) {
+			if _, err := findMatchingSignature([]Signature{signature}, arguments[:len(signature)], false); err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
 
+// Finds the signature whose parameters have the same types as the arguments.  For a variadic command the last parameter
+// of a signature also matches any further arguments of the same type.
+func findMatchingSignature(signatures []Signature, arguments []Argument, variadic bool) (Signature, error) {
 	for _, signature := range signatures {
-		if len(signature) != len(arguments) {
-			break
+		if len(signature) != len(arguments) && (!variadic || len(signature) == 0 || len(arguments) < len(signature)) {
+			continue
 		}
 
-		matchedSignature := signature
-		for tokenIndex, signatureToken := range signature {
-			if signatureToken != arguments[tokenIndex].token {
-				matchedSignature = nil
+		matched := true
+		for index, argument := range arguments {
+			parameter := signature[len(signature)-1]
+			if index < len(signature) {
+				parameter = signature[index]
+			}
+
+			if parameter != argument.token && (parameter != ParameterExpression || argument.token != expreval.TokenNumber) {
+				matched = false
 				break
 			}
 		}
 
-		if matchedSignature != nil {
-			return matchedSignature, nil
+		if matched {
+			return signature, nil
 		}
 	}
 
@@ -1,7 +1,10 @@
 package command
 
 import (
+	"alanmitic/gocalc/expreval"
 	"alanmitic/gocalc/resultformatter"
+	"bytes"
+	"errors"
 	"testing"
 )
 
@@ -9,7 +12,7 @@ func assertNilCommandAndError(t *testing.T, command Command, actualError error,
 	if command != nil {
 		t.Error("Expected:", nil, "Actual:", command.GetName())
 	}
-	if actualError != expectedError {
+	if !errors.Is(actualError, expectedError) {
 		t.Error("Expected:", expectedError, "Actual:", actualError)
 	}
 }
@@ -47,3 +50,119 @@ func assertOutputModeAndPrecision(t *testing.T, resultFormatter resultformatter.
 		t.Error("Expected:", expectedPrecision, "Actual:", actualPrecision)
 	}
 }
+
+func assertOutput(t *testing.T, output *bytes.Buffer, expectedOutput string) {
+	if output.String() != expectedOutput {
+		t.Error("Expected:", expectedOutput, "Actual:", output.String())
+	}
+}
+
+func TestParseCommandSignatures(t *testing.T) {
+	commandParser := NewCommandParser(expreval.NewEvaluator(), resultformatter.NewResultFormatter())
+
+	// Arguments of the wrong type match none of the signatures.
+	for _, input := range []string{"strict 1", "rename $a 2", "onerror $x", "unset $a 2"} {
+		command, _, err := commandParser.ParseCommand(input)
+		assertNilCommandAndError(t, command, err, ErrInvalidArgs)
+	}
+
+	// Expression parameters report the evaluation error.
+	for _, input := range []string{"fix abc", "history on", "exit - x"} {
+		command, _, err := commandParser.ParseCommand(input)
+		assertNilCommandAndError(t, command, err, expreval.ErrPrimaryExpected)
+	}
+
+	command, _, err := commandParser.ParseCommand("hex 3")
+	assertNilCommandAndError(t, command, err, ErrTooManyArgs)
+
+	command, arguments, err := commandParser.ParseCommand("exit -1")
+	if err != nil {
+		t.Error("Expected:", nil, "Actual:", err)
+	}
+	assertCommand(t, command, "exit")
+	assertArguments(t, arguments, []Argument{{expreval.TokenNumber, "", -1.0, 0, 0}})
+}
+
+func TestFindMatchingSignature(t *testing.T) {
+	signatures := []Signature{{}, {expreval.TokenNumber, expreval.TokenVariable}}
+	number := Argument{expreval.TokenNumber, "", 1.0, 0, 0}
+	variable := Argument{expreval.TokenVariable, "$a", 0.0, 0, 0}
+
+	signature, err := findMatchingSignature(signatures, []Argument{number, variable}, false)
+	if err != nil || len(signature) != 2 {
+		t.Error("Expected:", signatures[1], "Actual:", signature, err)
+	}
+
+	_, err = findMatchingSignature(signatures, []Argument{number, variable, variable}, false)
+	if err != ErrInvalidArgs {
+		t.Error("Expected:", ErrInvalidArgs, "Actual:", err)
+	}
+
+	signature, err = findMatchingSignature(signatures, []Argument{number, variable, variable}, true)
+	if err != nil || len(signature) != 2 {
+		t.Error("Expected:", signatures[1], "Actual:", signature, err)
+	}
+
+	_, err = findMatchingSignature(signatures, []Argument{number, variable, number}, true)
+	if err != ErrInvalidArgs {
+		t.Error("Expected:", ErrInvalidArgs, "Actual:", err)
+	}
+}
+
+func TestUsageError(t *testing.T) {
+	commandParser := NewCommandParser(expreval.NewEvaluator(), resultformatter.NewResultFormatter())
+	scriptRunner := commandParser.GetScriptRunner()
+
+	err := scriptRunner.ExecuteLine("fix abc")
+	if err == nil || err.Error() != "primary expected at column 5, usage: fix <precision>" {
+		t.Error("Expected:", "usage: fix <precision>", "Actual:", err)
+	}
+
+	err = scriptRunner.ExecuteLine("strict maybe")
+	if !errors.Is(err, ErrInvalidArgs) || err.Error() != "command arguments are invalid, usage: strict <on|off>" {
+		t.Error("Expected:", "usage: strict <on|off>", "Actual:", err)
+	}
+
+	// Errors other than invalid arguments do not show the usage.
+	err = scriptRunner.ExecuteLine("load /nonexistent/state.json")
+	var usageError *UsageError
+	if err == nil || errors.As(err, &usageError) {
+		t.Error("Expected:", "error without usage", "Actual:", err)
+	}
+}
+
+func TestParseCommandExpressionArguments(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	commandParser := NewCommandParser(evaluator, resultformatter.NewResultFormatter())
+	evaluator.Variables.SetVariable("$digits", 3)
+	evaluator.Variables.SetVariable("$ans", 7)
+
+	command, arguments, err := commandParser.ParseCommand("fix $digits + 1")
+	if err != nil {
+		t.Error("Expected:", nil, "Actual:", err)
+	}
+	assertCommand(t, command, "fix")
+	assertArguments(t, arguments, []Argument{{expreval.TokenNumber, "$digits + 1", 4.0, 0, 0}})
+
+	// Literal numbers are unchanged, and the evaluation leaves no result behind.
+	_, arguments, _ = commandParser.ParseCommand("fix 4")
+	assertArguments(t, arguments, []Argument{{expreval.TokenNumber, "", 4.0, 0, 0}})
+	_, arguments, _ = commandParser.ParseCommand("exit 2 + 2")
+	assertArguments(t, arguments, []Argument{{expreval.TokenNumber, "2 + 2", 4.0, 0, 0}})
+	if ans, _ := evaluator.Variables.GetVariable("$ans"); ans != 7 || len(evaluator.History) != 0 {
+		t.Error("Expected:", "no result", "Actual:", ans, evaluator.History)
+	}
+
+	// The error position is within the command line.
+	_, _, err = commandParser.ParseCommand("fix 1 + $nope")
+	var evaluationError *expreval.EvaluationError
+	if !errors.As(err, &evaluationError) || evaluationError.Column != 9 {
+		t.Error("Expected:", "error at column 9", "Actual:", err)
+	}
+
+	command, _, err = commandParser.ParseCommand("fix 1 / 0")
+	assertNilCommandAndError(t, command, err, expreval.ErrDivideByZero)
+
+	command, _, err = commandParser.ParseCommand("fix nan")
+	assertNilCommandAndError(t, command, err, ErrInvalidArgs)
+}
@@ -0,0 +1,295 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"alanmitic/gocalc/resultformatter"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+const (
+	PlotWidth  = 60
+	PlotHeight = 16
+)
+
+var ErrNothingToPlot = errors.New("expressions have no finite values in the range")
+
+// Each character of the chart is a braille pattern of two columns and four rows of dots, so that the first series is
+// drawn at a higher resolution than one point per character.
+const (
+	plotDotColumns = 2
+	plotDotRows    = 4
+)
+
+// Bits of each dot of a braille pattern, added to the blank pattern U+2800, by dot row and column.
+var brailleDots = [plotDotRows][plotDotColumns]rune{{0x01, 0x08}, {0x02, 0x10}, {0x04, 0x20}, {0x40, 0x80}}
+
+// Markers used to draw the series after the first, one per character, in order of the expressions.  A marker is drawn
+// over the braille dots of the first series where they meet.
+var plotMarkers = []rune{'●', '○', '◆', '◇', '▲'}
+
+// Braille pattern of a line through the middle of a character, shown in the legend for the first series.
+const plotLegendLine = "⠒⠒"
+
+type CommandPlot struct {
+	evaluator       *expreval.Evaluator
+	resultFormatter resultformatter.ResultFormatter
+}
+
+func (commandPlot *CommandPlot) GetName() string {
+	return "plot"
+}
+
+func (commandPlot *CommandPlot) GetSignatures() []Signature {
+	return []Signature{}
+}
+
+func (commandPlot *CommandPlot) GetMinExpressions() int {
+	// plot <expression>, <variable>, <from>, <to>
+	return 4
+}
+
+func (commandPlot *CommandPlot) Execute(arguments []Argument, output io.Writer) error {
+	lines, err := commandPlot.plot(arguments)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range lines {
+		fmt.Fprintln(output, line)
+	}
+
+	return nil
+}
+
+func (commandPlot *CommandPlot) GetUsage() (string, string) {
+	return "plot <expr>, ..., $var, <from>, <to>", "Plot expressions of $var over a range."
+}
+
+func (commandPlot *CommandPlot) GetHelp() (string, []string) {
+	description := "Plot up to six expressions of a variable over the range from one value to another, the first " +
+		"drawn with braille dots and each of the others with a different marker.  The y axis is scaled to fit the " +
+		"values and labelled using the current output format.  Values that cannot be evaluated are left as gaps."
+	return description, []string{"plot sin($x), $x, -pi, pi", "plot $x ^ 2, 2 * $x, $x, -2, 2"}
+}
+
+func (commandPlot *CommandPlot) plot(arguments []Argument) ([]string, error) {
+	numExpressions := len(arguments) - 3
+	if numExpressions < 1 || numExpressions > len(plotMarkers)+1 {
+		return nil, ErrInvalidArgs
+	}
+
+	variableName, err := parseSampleVariable(arguments[numExpressions])
+	if err != nil {
+		return nil, err
+	}
+
+	evaluator := commandPlot.evaluator
+	defer preserveEvaluatorState(evaluator, variableName, "$ans")()
+
+	from, err := evaluateSampleBound(evaluator, arguments[numExpressions+1])
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := evaluateSampleBound(evaluator, arguments[numExpressions+2])
+	if err != nil {
+		return nil, err
+	}
+
+	if from >= to {
+		return nil, ErrInvalidArgs
+	}
+
+	series := make([][]float64, numExpressions)
+	for seriesIndex := range series {
+		series[seriesIndex], err = sampleSeries(evaluator, arguments[seriesIndex].textValue, variableName, from, to)
+		if err != nil {
+			return nil, arguments[seriesIndex].positionError(err)
+		}
+	}
+
+	lines, err := renderPlot(series, from, to, commandPlot.resultFormatter)
+	if err != nil {
+		return nil, err
+	}
+
+	if numExpressions > 1 {
+		legend := []string{}
+		for seriesIndex := range series {
+			symbol := plotLegendLine
+			if seriesIndex > 0 {
+				symbol = string(plotMarkers[seriesIndex-1])
+			}
+			legend = append(legend, symbol+" "+arguments[seriesIndex].textValue)
+		}
+		lines = append(lines, "", strings.Join(legend, "   "))
+	}
+
+	return lines, nil
+}
+
+// Samples the expression once per column of dots.  Samples that fail to evaluate are stored as NaN so that they are
+// drawn as gaps, unless every sample fails in which case the first error is returned.
+func sampleSeries(evaluator *expreval.Evaluator, expression string, variableName string, from float64,
+	to float64) ([]float64, error) {
+	values := make([]float64, PlotWidth*plotDotColumns)
+	var firstErr error = nil
+	numValid := 0
+
+	for column := range values {
+		x := from + (to-from)*float64(column)/float64(len(values)-1)
+		y, err := sampleExpression(evaluator, expression, variableName, x)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			values[column] = math.NaN()
+		} else {
+			values[column] = y
+			numValid++
+		}
+	}
+
+	if numValid == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+
+	return values, nil
+}
+
+// Renders the first series as braille dots and the others as markers onto a chart with a y axis scaled to fit the
+// finite values.  NaN and infinite values are left as gaps.  Axis labels are formatted by the result formatter.
+func renderPlot(series [][]float64, from float64, to float64,
+	resultFormatter resultformatter.ResultFormatter) ([]string, error) {
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, values := range series {
+		for _, y := range values {
+			if !math.IsNaN(y) && !math.IsInf(y, 0) {
+				minY = math.Min(minY, y)
+				maxY = math.Max(maxY, y)
+			}
+		}
+	}
+
+	if minY > maxY {
+		return nil, ErrNothingToPlot
+	}
+
+	if minY == maxY {
+		minY--
+		maxY++
+	}
+
+	dots := make([][]rune, PlotHeight)
+	markers := make([][]rune, PlotHeight)
+	for row := range dots {
+		dots[row] = make([]rune, PlotWidth)
+		markers[row] = make([]rune, PlotWidth)
+	}
+
+	for dotColumn, y := range series[0] {
+		if !math.IsNaN(y) && !math.IsInf(y, 0) {
+			dotRow := plotDotRow(y, minY, maxY)
+			dot := brailleDots[dotRow%plotDotRows][dotColumn%plotDotColumns]
+			dots[dotRow/plotDotRows][dotColumn/plotDotColumns] |= dot
+		}
+	}
+
+	// The other series are drawn with the first sample in each character.
+	for seriesIndex, values := range series[1:] {
+		for column := 0; column < PlotWidth; column++ {
+			y := values[column*plotDotColumns]
+			if !math.IsNaN(y) && !math.IsInf(y, 0) {
+				markers[plotDotRow(y, minY, maxY)/plotDotRows][column] = plotMarkers[seriesIndex]
+			}
+		}
+	}
+
+	// Characters without markers or dots are blank, or part of the x axis line where it is in range.
+	zeroRow := -1
+	if minY <= 0.0 && maxY >= 0.0 {
+		zeroRow = plotDotRow(0.0, minY, maxY) / plotDotRows
+	}
+
+	grid := make([][]rune, PlotHeight)
+	for row := range grid {
+		grid[row] = make([]rune, PlotWidth)
+		for column, pattern := range dots[row] {
+			switch {
+			case markers[row][column] != 0:
+				grid[row][column] = markers[row][column]
+			case pattern != 0:
+				grid[row][column] = '\u2800' + pattern
+			case row == zeroRow:
+				grid[row][column] = '─'
+			default:
+				grid[row][column] = ' '
+			}
+		}
+	}
+
+	// Label the top, middle and bottom rows of the y axis, with the value of the dots at the top, middle and bottom.
+	numDotRows := PlotHeight * plotDotRows
+	middleDotRow := (numDotRows - 1) / 2
+	middleY := maxY - (maxY-minY)*float64(middleDotRow)/float64(numDotRows-1)
+	yLabels := map[int]string{
+		0:                          resultFormatter.FormatValue(maxY),
+		middleDotRow / plotDotRows: resultFormatter.FormatValue(middleY),
+		PlotHeight - 1:             resultFormatter.FormatValue(minY),
+	}
+
+	labelWidth := 0
+	for _, label := range yLabels {
+		labelWidth = maxInt(labelWidth, len(label))
+	}
+
+	lines := []string{}
+	for row, cells := range grid {
+		line := fmt.Sprintf("%*s │%s", labelWidth, "", string(cells))
+		if label, found := yLabels[row]; found {
+			line = fmt.Sprintf("%*s ┤%s", labelWidth, label, string(cells))
+		}
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+	lines = append(lines, strings.Repeat(" ", labelWidth)+" └"+strings.Repeat("─", PlotWidth))
+
+	// Label the start and end of the x axis, and the middle when there is room.
+	fromLabel := resultFormatter.FormatValue(from)
+	midLabel := resultFormatter.FormatValue((from + to) / 2)
+	toLabel := resultFormatter.FormatValue(to)
+	axisStart := labelWidth + 2
+	toStart := axisStart + maxInt(PlotWidth-len(toLabel), len(fromLabel)+1)
+	midStart := axisStart + (PlotWidth-len(midLabel))/2
+	xLabels := []byte(strings.Repeat(" ", toStart+len(toLabel)))
+	copy(xLabels[axisStart:], fromLabel)
+	if midStart > axisStart+len(fromLabel) && midStart+len(midLabel) < toStart {
+		copy(xLabels[midStart:], midLabel)
+	}
+	copy(xLabels[toStart:], toLabel)
+	lines = append(lines, strings.TrimRight(string(xLabels), " "))
+
+	return lines, nil
+}
+
+// Gets the row of dots for a value, counting down from the top of the chart.
+func plotDotRow(y float64, minY float64, maxY float64) int {
+	return int(math.Round((maxY - y) / (maxY - minY) * float64(PlotHeight*plotDotRows-1)))
+}
+
+func NewCommandPlot(evaluator *expreval.Evaluator, resultFormatter resultformatter.ResultFormatter) Command {
+	command := CommandPlot{}
+	command.evaluator = evaluator
+	command.resultFormatter = resultFormatter
+	return &command
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,140 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"alanmitic/gocalc/resultformatter"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCommandPlot(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	resultFormatter.SetOutputMode(resultformatter.OutputModeFixed)
+	resultFormatter.SetPrecision(1)
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	command, arguments, _ := commandParser.ParseCommand("plot 2 * $x ^ 2, $x, -(1), 1\n")
+	assertCommand(t, command, "plot")
+	assertArguments(t, arguments, []Argument{
+		{expreval.TokenEnd, "2 * $x ^ 2", 0.0, 0, 0},
+		{expreval.TokenEnd, "$x", 0.0, 0, 0},
+		{expreval.TokenEnd, "-(1)", 0.0, 0, 0},
+		{expreval.TokenEnd, "1", 0.0, 0, 0}})
+
+	lines, err := command.(*CommandPlot).plot(arguments)
+	if err != nil {
+		t.Fatal("Expected:", nil, "Actual:", err)
+	}
+
+	// The series is drawn with braille dots, two columns and four rows to a character.
+	assertPlotLine(t, lines[0], "2.0 ┤⠡ ")
+	assertPlotLine(t, lines[1], "    │ ⠡ ")
+	assertPlotLine(t, lines[PlotHeight-1], "0.0 ┤")
+	assertPlotLine(t, lines[PlotHeight], "    └───")
+	assertPlotLine(t, lines[PlotHeight+1], "     -1.0")
+
+	if len(lines) != PlotHeight+2 {
+		t.Error("Expected:", PlotHeight+2, "Actual:", len(lines))
+	}
+
+	// Sampling must not leave the variable or $ans behind.
+	if _, found := evaluator.Variables.GetVariable("$x"); found {
+		t.Error("Variable not removed: $x")
+	}
+	if _, found := evaluator.Variables.GetVariable("$ans"); found {
+		t.Error("Variable not removed: $ans")
+	}
+}
+
+func TestCommandPlotMultipleExpressions(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	command, arguments, _ := commandParser.ParseCommand("plot $x, 2 * $x, 3 * $x, $x, 0, 1")
+
+	lines, err := command.(*CommandPlot).plot(arguments)
+	if err != nil {
+		t.Fatal("Expected:", nil, "Actual:", err)
+	}
+
+	// The first series is drawn with braille dots and the others with markers, shown in the legend.
+	legend := lines[len(lines)-1]
+	if legend != "⠒⠒ $x   ● 2 * $x   ○ 3 * $x" {
+		t.Error("Expected:", "⠒⠒ $x   ● 2 * $x   ○ 3 * $x", "Actual:", legend)
+	}
+
+	// The series end at different heights, and meet at the start of the range where the last marker is drawn.
+	chart := strings.Join(lines[:PlotHeight], "\n")
+	if !strings.HasSuffix(lines[0], "○") || !strings.Contains(chart, "●") || !strings.Contains(chart, "⠊") {
+		t.Error("Expected:", "series drawn with ⠊, ● and ○", "Actual:", chart)
+	}
+	if !strings.Contains(lines[PlotHeight-1], "┤○") {
+		t.Error("Expected:", "┤○", "Actual:", lines[PlotHeight-1])
+	}
+}
+
+func TestCommandPlotGaps(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	command, arguments, _ := commandParser.ParseCommand("plot 1 / $x, $x, -1, 1")
+
+	// The divide by zero at $x = 0 is not sampled, so the plot succeeds.
+	_, err := command.(*CommandPlot).plot(arguments)
+	if err != nil {
+		t.Error("Expected:", nil, "Actual:", err)
+	}
+
+	command, arguments, _ = commandParser.ParseCommand("plot 1 / ($x - $x), $x, -1, 1")
+	_, err = command.(*CommandPlot).plot(arguments)
+	if !errors.Is(err, expreval.ErrDivideByZero) {
+		t.Error("Expected:", expreval.ErrDivideByZero, "Actual:", err)
+	}
+}
+
+func TestCommandPlotInvalidArgs(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	command, _, err := commandParser.ParseCommand("plot $x, $x, 0")
+	assertNilCommandAndError(t, command, err, ErrInvalidArgs)
+
+	command, arguments, _ := commandParser.ParseCommand("plot $x, 2, 0, 1")
+	err = command.Execute(arguments, io.Discard)
+	if err != ErrInvalidArgs {
+		t.Error("Expected:", ErrInvalidArgs, "Actual:", err)
+	}
+
+	command, arguments, _ = commandParser.ParseCommand("plot $x, $x, 1, 0")
+	err = command.Execute(arguments, io.Discard)
+	if err != ErrInvalidArgs {
+		t.Error("Expected:", ErrInvalidArgs, "Actual:", err)
+	}
+}
+
+func TestCommandPlotErrorPosition(t *testing.T) {
+	commandParser := NewCommandParser(expreval.NewEvaluator(), resultformatter.NewResultFormatter())
+
+	// The position of an error in an expression or a bound is shown in the line.
+	for _, input := range []string{"plot $x, $x, 0, 1 + $zz", "plot  $zz * $x, $x, 0, 1"} {
+		err := commandParser.GetScriptRunner().ExecuteLine(input)
+		output := new(bytes.Buffer)
+		PrintError(output, err, input)
+		column := strings.Index(input, "$zz") + 1
+		expectedOutput := "  " + input + "\n" + strings.Repeat(" ", column+1) + "^\n"
+		if !strings.Contains(output.String(), fmt.Sprint("at column ", column)) ||
+			!strings.HasSuffix(output.String(), expectedOutput) {
+			t.Error("Input:", input, "Expected:", expectedOutput, "Actual:", output)
+		}
+	}
+}
+
+func assertPlotLine(t *testing.T, line string, expectedPrefix string) {
+	if !strings.HasPrefix(line, expectedPrefix) {
+		t.Error("Expected prefix:", expectedPrefix, "Actual:", line)
+	}
+}
@@ -0,0 +1,61 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type CommandPrompt struct {
+	scriptRunner *ScriptRunner
+}
+
+func (commandPrompt *CommandPrompt) GetName() string {
+	return "prompt"
+}
+
+func (commandPrompt *CommandPrompt) GetSignatures() []Signature {
+	return []Signature{}
+}
+
+func (commandPrompt *CommandPrompt) IsTextRequired() bool {
+	// prompt [text]
+	return false
+}
+
+func (commandPrompt *CommandPrompt) Execute(arguments []Argument, output io.Writer) error {
+	if len(arguments) == 0 {
+		fmt.Fprintln(output, "prompt", strconv.Quote(commandPrompt.scriptRunner.Prompt))
+		return nil
+	}
+
+	// Quotes keep any spaces at the ends of the prompt.
+	prompt := arguments[0].textValue
+	if strings.HasPrefix(prompt, "\"") {
+		unquoted, err := strconv.Unquote(prompt)
+		if err != nil {
+			return ErrInvalidArgs
+		}
+		prompt = unquoted
+	}
+
+	commandPrompt.scriptRunner.Prompt = prompt
+	return nil
+}
+
+func (commandPrompt *CommandPrompt) GetUsage() (string, string) {
+	return "prompt <text>", "Set or show the prompt, quoted to keep spaces, e.g. prompt \"calc> \"."
+}
+
+func (commandPrompt *CommandPrompt) GetHelp() (string, []string) {
+	description := "Set the prompt shown before each line of input, or show it when no text is given.  Quote the " +
+		"prompt to keep spaces at its ends."
+	return description, []string{"prompt", "prompt \"calc> \""}
+}
+
+func NewCommandPrompt(scriptRunner *ScriptRunner) Command {
+	command := CommandPrompt{}
+	command.scriptRunner = scriptRunner
+	return &command
+}
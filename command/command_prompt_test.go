@@ -0,0 +1,34 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"alanmitic/gocalc/resultformatter"
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestCommandPrompt(t *testing.T) {
+	commandParser := NewCommandParser(expreval.NewEvaluator(), resultformatter.NewResultFormatter())
+	scriptRunner := commandParser.GetScriptRunner()
+	output := new(bytes.Buffer)
+	scriptRunner.Output = output
+
+	scriptRunner.ExecuteLine("prompt")
+	assertOutput(t, output, "prompt \"gocalc >> \"\n")
+
+	scriptRunner.ExecuteLine("prompt  calc>  ")
+	if scriptRunner.Prompt != "calc>" {
+		t.Error("Expected:", "calc>", "Actual:", scriptRunner.Prompt)
+	}
+
+	scriptRunner.ExecuteLine("prompt \"[rpn] \"")
+	if scriptRunner.Prompt != "[rpn] " {
+		t.Error("Expected:", "[rpn] ", "Actual:", scriptRunner.Prompt)
+	}
+
+	err := scriptRunner.ExecuteLine("prompt \"unterminated")
+	if !errors.Is(err, ErrInvalidArgs) {
+		t.Error("Expected:", ErrInvalidArgs, "Actual:", err)
+	}
+}
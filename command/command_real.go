@@ -3,6 +3,7 @@ package command
 import (
 	"alanmitic/gocalc/expreval"
 	"alanmitic/gocalc/resultformatter"
+	"io"
 )
 
 const (
@@ -22,14 +23,19 @@ func (commandReal *CommandReal) GetSignatures() []Signature {
 		// real
 		[]expreval.LexAnToken{},
 		// real <n>
-		[]expreval.LexAnToken{expreval.TokenNumber}}
+		[]expreval.LexAnToken{ParameterExpression}}
 }
 
-func (commandReal *CommandReal) Execute(arguments []Argument) error {
+func (commandReal *CommandReal) Execute(arguments []Argument, output io.Writer) error {
 	precision := DefaultRealPrecision
 	if len(arguments) == 1 {
-		precision = int(arguments[0].numericValue)
+		var err error
+		precision, err = arguments[0].GetIntegerValue(0, resultformatter.MaxPrecision)
+		if err != nil {
+			return err
+		}
 	}
+
 	commandReal.resultFormatter.SetOutputMode(resultformatter.OutputModeReal)
 	commandReal.resultFormatter.SetPrecision(precision)
 	return nil
@@ -39,6 +45,13 @@ func (commandReal *CommandReal) GetUsage() (string, string) {
 	return "real <precision>", "Set real output mode with optional precision."
 }
 
+func (commandReal *CommandReal) GetHelp() (string, []string) {
+	description := "Output results in real notation, choosing between fixed and scientific notation, with the " +
+		"precision as the number of significant digits up to 17.  Without a precision the fewest digits that " +
+		"represent the value are used."
+	return description, []string{"real", "real 5"}
+}
+
 func NewCommandReal(resultFormatter resultformatter.ResultFormatter) Command {
 	command := CommandReal{}
 	command.resultFormatter = resultFormatter
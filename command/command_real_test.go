@@ -3,6 +3,7 @@ package command
 import (
 	"alanmitic/gocalc/expreval"
 	"alanmitic/gocalc/resultformatter"
+	"io"
 	"testing"
 )
 
@@ -14,7 +15,7 @@ func TestCommandRealNoPrecision(t *testing.T) {
 	assertCommand(t, command, "real")
 	assertArguments(t, arguments, []Argument{})
 
-	command.Execute(arguments)
+	command.Execute(arguments, io.Discard)
 	assertOutputModeAndPrecision(t, resultFormatter, resultformatter.OutputModeReal, -1)
 }
 
@@ -24,9 +25,9 @@ func TestCommandRealWithPrecision(t *testing.T) {
 	commandParser := NewCommandParser(evaluator, resultFormatter)
 	command, arguments, _ := commandParser.ParseCommand("real 4")
 	assertCommand(t, command, "real")
-	assertArguments(t, arguments, []Argument{{expreval.TokenNumber, "", 4.0}})
+	assertArguments(t, arguments, []Argument{{expreval.TokenNumber, "", 4.0, 0, 0}})
 
-	command.Execute(arguments)
+	command.Execute(arguments, io.Discard)
 	assertOutputModeAndPrecision(t, resultFormatter, resultformatter.OutputModeReal, 4)
 }
 
@@ -37,3 +38,16 @@ func TestCommandRealWithTooManyArgs(t *testing.T) {
 	command, _, err := commandParser.ParseCommand("real 2 5")
 	assertNilCommandAndError(t, command, err, ErrTooManyArgs)
 }
+
+func TestCommandRealWithInvalidPrecision(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	command, arguments, _ := commandParser.ParseCommand("real 0.5")
+
+	err := command.Execute(arguments, io.Discard)
+	if err != ErrInvalidArgs {
+		t.Error("Expected:", ErrInvalidArgs, "Actual:", err)
+	}
+	assertOutputModeAndPrecision(t, resultFormatter, resultformatter.OutputModeReal, -1)
+}
@@ -0,0 +1,186 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"alanmitic/gocalc/resultformatter"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// Command of an example extension, which shows the tax on an amount.
+type commandTax struct {
+	name string
+	rate float64
+}
+
+func (commandTax *commandTax) GetName() string {
+	return commandTax.name
+}
+
+func (commandTax *commandTax) GetSignatures() []Signature {
+	return []Signature{
+		// tax <amount>
+		[]expreval.LexAnToken{ParameterExpression}}
+}
+
+func (commandTax *commandTax) Execute(arguments []Argument, output io.Writer) error {
+	if len(arguments) != 1 || arguments[0].GetToken() != expreval.TokenNumber {
+		return ErrInvalidArgs
+	}
+
+	fmt.Fprintln(output, "Tax:", arguments[0].GetNumericValue()*commandTax.rate)
+	return nil
+}
+
+func (commandTax *commandTax) GetUsage() (string, string) {
+	return commandTax.name + " <amount>", "Show the tax on an amount."
+}
+
+func newCommandParserWithExtension(t *testing.T) (*CommandParser, *bytes.Buffer) {
+	evaluator := expreval.NewEvaluator()
+	commandParser := NewCommandParser(evaluator, resultformatter.NewResultFormatter())
+	output := new(bytes.Buffer)
+	commandParser.GetScriptRunner().Output = output
+
+	err := commandParser.Register(&commandTax{"tax", 0.2})
+	if err != nil {
+		t.Fatal("Expected:", nil, "Actual:", err)
+	}
+
+	err = commandParser.RegisterFunction("gross", 1, func(arguments []float64) (float64, error) {
+		return arguments[0] * 1.2, nil
+	})
+	if err != nil {
+		t.Fatal("Expected:", nil, "Actual:", err)
+	}
+
+	return commandParser, output
+}
+
+func TestRegisterCommand(t *testing.T) {
+	commandParser, output := newCommandParserWithExtension(t)
+	scriptRunner := commandParser.GetScriptRunner()
+
+	err := scriptRunner.ExecuteLine("tax 100 + 50")
+	if err != nil {
+		t.Error("Expected:", nil, "Actual:", err)
+	}
+	assertOutput(t, output, "Tax: 30\n")
+
+	// The command is matched by prefixes and listed by help.
+	command, _, err := commandParser.ParseCommand("ta 1")
+	assertNilCommandAndError(t, command, err, ErrAmbiguousCommand)
+	if err.Error() != "ambiguous command: ta could be table, tax" {
+		t.Error("Expected:", "ambiguous command: ta could be table, tax", "Actual:", err)
+	}
+
+	output.Reset()
+	scriptRunner.ExecuteLine("help tax")
+	assertOutput(t, output, "tax <amount>\n\nShow the tax on an amount.\n")
+
+	scriptRunner.ExecuteLine("help")
+	if !strings.Contains(output.String(), "tax <amount>") {
+		t.Error("Expected:", "tax <amount>", "Actual:", output.String())
+	}
+
+	command, _, err = commandParser.ParseCommand("tax")
+	assertNilCommandAndError(t, command, err, ErrInvalidArgs)
+	if err.Error() != "command arguments are invalid, usage: tax <amount>" {
+		t.Error("Expected:", "usage: tax <amount>", "Actual:", err)
+	}
+}
+
+func TestRegisterFunction(t *testing.T) {
+	commandParser, output := newCommandParserWithExtension(t)
+	scriptRunner := commandParser.GetScriptRunner()
+
+	err := scriptRunner.ExecuteLine("gross(100) - 100")
+	if err != nil {
+		t.Error("Expected:", nil, "Actual:", err)
+	}
+	assertOutput(t, output, "20\n")
+
+	// A function that is a prefix of commands starts an expression rather than a command.
+	commandParser.RegisterFunction("he", 0, func(arguments []float64) (float64, error) {
+		return 1, nil
+	})
+	output.Reset()
+	scriptRunner.ExecuteLine("he() + 1")
+	assertOutput(t, output, "2\n")
+
+	scriptRunner.ExecuteLine("rpn")
+	output.Reset()
+	scriptRunner.ExecuteLine("50 gross")
+	assertOutput(t, output, "1: 60\n")
+	scriptRunner.ExecuteLine("alg")
+
+	output.Reset()
+	scriptRunner.ExecuteLine("help functions")
+	if !strings.HasSuffix(output.String(), "\nFunctions:\n  gross(x)\n  he()\n") {
+		t.Error("Expected:", "Functions: gross(x) he()", "Actual:", output.String())
+	}
+
+	completer := NewCompleter(commandParser, commandParser.evaluator)
+	assertCompletion(t, completer, "1 + gr", 4, []string{"gross"})
+	assertCompletion(t, completer, "ta", 0, []string{"table", "tax"})
+}
+
+func TestRegisterNameConflicts(t *testing.T) {
+	commandParser, _ := newCommandParserWithExtension(t)
+	commandParser.AddAlias("f", "fix")
+	commandParser.GetScriptRunner().ExecuteLine("macro m")
+	commandParser.GetScriptRunner().ExecuteLine("end")
+
+	for _, name := range []string{"tax", "fix", "f", "m", "gross"} {
+		err := commandParser.Register(&commandTax{name, 0.1})
+		if !errors.Is(err, ErrNameConflict) {
+			t.Error("Name:", name, "Expected:", ErrNameConflict, "Actual:", err)
+		}
+	}
+
+	for _, name := range []string{"$tax", "inf", "2x", ""} {
+		err := commandParser.Register(&commandTax{name, 0.1})
+		if !errors.Is(err, ErrInvalidArgs) {
+			t.Error("Name:", name, "Expected:", ErrInvalidArgs, "Actual:", err)
+		}
+	}
+
+	for _, name := range []string{"tax", "fix", "f", "m"} {
+		err := commandParser.RegisterFunction(name, 1, func(arguments []float64) (float64, error) {
+			return arguments[0], nil
+		})
+		if !errors.Is(err, ErrNameConflict) {
+			t.Error("Name:", name, "Expected:", ErrNameConflict, "Actual:", err)
+		}
+	}
+
+	// The built in command still runs after its name is refused as a function.
+	command, _, err := commandParser.ParseCommand("fix 4")
+	assertCommand(t, command, "fix")
+	if err != nil {
+		t.Error("Expected:", nil, "Actual:", err)
+	}
+
+	err = commandParser.AddAlias("gross", "fix")
+	if !errors.Is(err, ErrNameConflict) {
+		t.Error("Expected:", ErrNameConflict, "Actual:", err)
+	}
+
+	err = commandParser.AddAlias("t", "tax")
+	if err != nil {
+		t.Error("Expected:", nil, "Actual:", err)
+	}
+}
+
+func TestFormatFunctionUsage(t *testing.T) {
+	usages := map[int]string{0: "f()", 1: "f(x)", 3: "f(x1, x2, x3)", expreval.VariadicArity: "f(...)"}
+	for arity, expectedUsage := range usages {
+		if usage := formatFunctionUsage("f", arity); usage != expectedUsage {
+			t.Error("Arity:", arity, "Expected:", expectedUsage, "Actual:", usage)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"io"
+)
+
+type CommandRename struct {
+	evaluator *expreval.Evaluator
+}
+
+func (commandRename *CommandRename) GetName() string {
+	return "rename"
+}
+
+func (commandRename *CommandRename) GetSignatures() []Signature {
+	return []Signature{
+		// rename <variable> <variable>
+		[]expreval.LexAnToken{expreval.TokenVariable, expreval.TokenVariable}}
+}
+
+func (commandRename *CommandRename) Execute(arguments []Argument, output io.Writer) error {
+	if len(arguments) != 2 || arguments[0].token != expreval.TokenVariable ||
+		arguments[1].token != expreval.TokenVariable {
+		return ErrInvalidArgs
+	}
+
+	return commandRename.evaluator.RenameVariable(arguments[0].textValue, arguments[1].textValue)
+}
+
+func (commandRename *CommandRename) GetUsage() (string, string) {
+	return "rename <variable> <new variable>", "Rename a variable or constant."
+}
+
+func (commandRename *CommandRename) GetHelp() (string, []string) {
+	description := "Rename a variable or constant, replacing any variable that already has the new name.  A constant " +
+		"keeps being a constant, and cannot be replaced."
+	return description, []string{"rename $a $area"}
+}
+
+func NewCommandRename(evaluator *expreval.Evaluator) Command {
+	command := CommandRename{}
+	command.evaluator = evaluator
+	return &command
+}
@@ -0,0 +1,33 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"alanmitic/gocalc/resultformatter"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestCommandRename(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	evaluator.Variables.SetVariable("$old", 3)
+
+	command, arguments, _ := commandParser.ParseCommand("rename $old $new")
+	assertCommand(t, command, "rename")
+	err := command.Execute(arguments, io.Discard)
+	if err != nil {
+		t.Error("Expected:", nil, "Actual:", err)
+	}
+
+	assertScriptVariable(t, evaluator, "$new", 3)
+	if _, found := evaluator.Variables.GetVariable("$old"); found {
+		t.Error("Variable should not be defined: $old")
+	}
+
+	err = command.Execute(arguments, io.Discard)
+	if !errors.Is(err, expreval.ErrUndefinedVariable) {
+		t.Error("Expected:", expreval.ErrUndefinedVariable, "Actual:", err)
+	}
+}
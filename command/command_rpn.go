@@ -0,0 +1,44 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"io"
+)
+
+type CommandRPN struct {
+	scriptRunner *ScriptRunner
+}
+
+func (commandRPN *CommandRPN) GetName() string {
+	return "rpn"
+}
+
+func (commandRPN *CommandRPN) GetSignatures() []Signature {
+	return []Signature{
+		// rpn
+		[]expreval.LexAnToken{}}
+}
+
+func (commandRPN *CommandRPN) Execute(arguments []Argument, output io.Writer) error {
+	commandRPN.scriptRunner.RPN = true
+	commandRPN.scriptRunner.PrintRPNStack()
+	return nil
+}
+
+func (commandRPN *CommandRPN) GetUsage() (string, string) {
+	return "rpn", "Set reverse Polish input with a stack, e.g. 3 4 + 2 *, and swap, drop, dup, roll, neg and clear."
+}
+
+func (commandRPN *CommandRPN) GetHelp() (string, []string) {
+	description := "Switch to reverse Polish input, where values are pushed on to a stack and operators replace the " +
+		"values they use with the result.  The stack is shown after each line with the top as level 1, " +
+		"and the top is kept in $ans.  A minus joined to a number makes it negative, $var = assigns the " +
+		"top of the stack, and dup, drop, swap, roll, neg and clear change the stack."
+	return description, []string{"rpn", "3 4 + 2 *", "9.8 $g ="}
+}
+
+func NewCommandRPN(scriptRunner *ScriptRunner) Command {
+	command := CommandRPN{}
+	command.scriptRunner = scriptRunner
+	return &command
+}
@@ -0,0 +1,53 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"alanmitic/gocalc/resultformatter"
+	"bytes"
+	"testing"
+)
+
+func TestCommandRPN(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	scriptRunner := commandParser.GetScriptRunner()
+	output := new(bytes.Buffer)
+	scriptRunner.Output = output
+
+	scriptRunner.ExecuteLine("rpn")
+	assertOutput(t, output, "Stack empty\n")
+
+	// Commands still work in RPN mode, and results are formatted the same.
+	output.Reset()
+	scriptRunner.ExecuteLine("3 4 + 2")
+	scriptRunner.ExecuteLine("hex")
+	scriptRunner.ExecuteLine("*")
+	assertOutput(t, output, "2: 7\n1: 2\n1: 0000000e\n")
+
+	output.Reset()
+	scriptRunner.ExecuteLine("alg")
+	scriptRunner.ExecuteLine("$ans + 2")
+	assertOutput(t, output, "00000010\n")
+
+	// The stack is kept while in algebraic mode.
+	output.Reset()
+	scriptRunner.ExecuteLine("rpn")
+	assertOutput(t, output, "1: 0000000e\n")
+}
+
+func TestCommandRPNStackOperationsBeforeCommands(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	commandParser := NewCommandParser(evaluator, resultformatter.NewResultFormatter())
+	scriptRunner := commandParser.GetScriptRunner()
+	scriptRunner.RPN = true
+
+	if !scriptRunner.isRPNInput("dup *") || !scriptRunner.isRPNInput("2 dup") || !scriptRunner.isRPNInput("foo") ||
+		!scriptRunner.isRPNInput("clear") {
+		t.Error("Expected:", "RPN input")
+	}
+
+	if scriptRunner.isRPNInput("fix 2") || scriptRunner.isRPNInput("alg") || scriptRunner.isRPNInput("clear vars") {
+		t.Error("Expected:", "command")
+	}
+}
@@ -0,0 +1,55 @@
+package command
+
+import (
+	"io"
+)
+
+type CommandSave struct {
+	commandParser *CommandParser
+}
+
+func (commandSave *CommandSave) GetName() string {
+	return "save"
+}
+
+func (commandSave *CommandSave) GetSignatures() []Signature {
+	return []Signature{}
+}
+
+func (commandSave *CommandSave) IsTextRequired() bool {
+	// save [file]
+	return false
+}
+
+func (commandSave *CommandSave) Execute(arguments []Argument, output io.Writer) error {
+	fileName, err := getStateFileName(arguments)
+	if err != nil {
+		return err
+	}
+
+	return SaveStateFile(fileName, commandSave.commandParser)
+}
+
+func (commandSave *CommandSave) GetUsage() (string, string) {
+	return "save <file>", "Save the variables, settings, macros and aliases, by default to the state file."
+}
+
+func (commandSave *CommandSave) GetHelp() (string, []string) {
+	description := "Save the variables, constants, output format, settings, macros and aliases to a JSON file, by " +
+		"default the state file in the user configuration directory that --autosave uses."
+	return description, []string{"save", "save rates.json"}
+}
+
+func NewCommandSave(commandParser *CommandParser) Command {
+	command := CommandSave{}
+	command.commandParser = commandParser
+	return &command
+}
+
+// Gets the file name given as the argument, or the default state file.
+func getStateFileName(arguments []Argument) (string, error) {
+	if len(arguments) == 1 {
+		return arguments[0].textValue, nil
+	}
+	return DefaultStateFile()
+}
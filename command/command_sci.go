@@ -3,6 +3,7 @@ package command
 import (
 	"alanmitic/gocalc/expreval"
 	"alanmitic/gocalc/resultformatter"
+	"io"
 )
 
 const (
@@ -22,14 +23,19 @@ func (commandSci *CommandSci) GetSignatures() []Signature {
 		// sci
 		[]expreval.LexAnToken{},
 		// sci <n>
-		[]expreval.LexAnToken{expreval.TokenNumber}}
+		[]expreval.LexAnToken{ParameterExpression}}
 }
 
-func (commandSci *CommandSci) Execute(arguments []Argument) error {
+func (commandSci *CommandSci) Execute(arguments []Argument, output io.Writer) error {
 	precision := DefaultSciPrecision
 	if len(arguments) == 1 {
-		precision = int(arguments[0].numericValue)
+		var err error
+		precision, err = arguments[0].GetIntegerValue(0, resultformatter.MaxPrecision)
+		if err != nil {
+			return err
+		}
 	}
+
 	commandSci.resultFormatter.SetOutputMode(resultformatter.OutputModeScientific)
 	commandSci.resultFormatter.SetPrecision(precision)
 	return nil
@@ -39,6 +45,12 @@ func (commandSci *CommandSci) GetUsage() (string, string) {
 	return "sci <precision>", "Set scientific output mode with optional precision."
 }
 
+func (commandSci *CommandSci) GetHelp() (string, []string) {
+	description := "Output results in scientific notation, with the precision as the number of digits after the " +
+		"decimal point from 0 to 17, 2 if it is not given."
+	return description, []string{"sci", "sci 3"}
+}
+
 func NewCommandSci(resultFormatter resultformatter.ResultFormatter) Command {
 	command := CommandSci{}
 	command.resultFormatter = resultFormatter
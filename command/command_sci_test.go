@@ -3,6 +3,7 @@ package command
 import (
 	"alanmitic/gocalc/expreval"
 	"alanmitic/gocalc/resultformatter"
+	"io"
 	"testing"
 )
 
@@ -14,7 +15,7 @@ func TestCommandSciNoPrecision(t *testing.T) {
 	assertCommand(t, command, "sci")
 	assertArguments(t, arguments, []Argument{})
 
-	command.Execute(arguments)
+	command.Execute(arguments, io.Discard)
 	assertOutputModeAndPrecision(t, resultFormatter, resultformatter.OutputModeScientific, 2)
 }
 
@@ -24,9 +25,9 @@ func TestCommandSciWithPrecision(t *testing.T) {
 	commandParser := NewCommandParser(evaluator, resultFormatter)
 	command, arguments, _ := commandParser.ParseCommand("sci 4")
 	assertCommand(t, command, "sci")
-	assertArguments(t, arguments, []Argument{{expreval.TokenNumber, "", 4.0}})
+	assertArguments(t, arguments, []Argument{{expreval.TokenNumber, "", 4.0, 0, 0}})
 
-	command.Execute(arguments)
+	command.Execute(arguments, io.Discard)
 	assertOutputModeAndPrecision(t, resultFormatter, resultformatter.OutputModeScientific, 4)
 }
 
@@ -37,3 +38,16 @@ func TestCommandSciWithTooManyArgs(t *testing.T) {
 	command, _, err := commandParser.ParseCommand("sci 2 5")
 	assertNilCommandAndError(t, command, err, ErrTooManyArgs)
 }
+
+func TestCommandSciWithInvalidPrecision(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	command, arguments, _ := commandParser.ParseCommand("sci 10 ^ 10")
+
+	err := command.Execute(arguments, io.Discard)
+	if err != ErrInvalidArgs {
+		t.Error("Expected:", ErrInvalidArgs, "Actual:", err)
+	}
+	assertOutputModeAndPrecision(t, resultFormatter, resultformatter.OutputModeReal, -1)
+}
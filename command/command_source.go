@@ -0,0 +1,45 @@
+package command
+
+import (
+	"io"
+)
+
+type CommandSource struct {
+	scriptRunner *ScriptRunner
+}
+
+func (commandSource *CommandSource) GetName() string {
+	return "source"
+}
+
+func (commandSource *CommandSource) GetSignatures() []Signature {
+	return []Signature{}
+}
+
+func (commandSource *CommandSource) IsTextRequired() bool {
+	// source <file>
+	return true
+}
+
+func (commandSource *CommandSource) Execute(arguments []Argument, output io.Writer) error {
+	return commandSource.scriptRunner.runWithOutput(output, func() error {
+		return commandSource.scriptRunner.RunFile(arguments[0].textValue)
+	})
+}
+
+func (commandSource *CommandSource) GetUsage() (string, string) {
+	return "source <file>", "Run the commands and expressions in a script file."
+}
+
+func (commandSource *CommandSource) GetHelp() (string, []string) {
+	description := "Run the commands and expressions in a script file as if they were typed at the prompt.  Blank " +
+		"lines and lines starting with # are ignored, and errors are reported with the file name and line " +
+		"number."
+	return description, []string{"source file.gc"}
+}
+
+func NewCommandSource(scriptRunner *ScriptRunner) Command {
+	command := CommandSource{}
+	command.scriptRunner = scriptRunner
+	return &command
+}
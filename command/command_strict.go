@@ -0,0 +1,65 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"fmt"
+	"io"
+)
+
+type CommandStrict struct {
+	evaluator *expreval.Evaluator
+}
+
+func (commandStrict *CommandStrict) GetName() string {
+	return "strict"
+}
+
+func (commandStrict *CommandStrict) GetSignatures() []Signature {
+	return []Signature{
+		// strict
+		[]expreval.LexAnToken{},
+		// strict <on|off>
+		[]expreval.LexAnToken{expreval.TokenIdentifier}}
+}
+
+func (commandStrict *CommandStrict) Execute(arguments []Argument, output io.Writer) error {
+	if len(arguments) == 0 {
+		if commandStrict.evaluator.Strict {
+			fmt.Fprintln(output, "strict on")
+		} else {
+			fmt.Fprintln(output, "strict off")
+		}
+		return nil
+	}
+
+	switch arguments[0].textValue {
+	case "on":
+		commandStrict.evaluator.Strict = true
+	case "off":
+		commandStrict.evaluator.Strict = false
+	default:
+		return ErrInvalidArgs
+	}
+
+	return nil
+}
+
+func (commandStrict *CommandStrict) GetKeywords() []string {
+	return []string{"on", "off"}
+}
+
+func (commandStrict *CommandStrict) GetUsage() (string, string) {
+	return "strict <on|off>", "Set or show whether undefined variables are an error."
+}
+
+func (commandStrict *CommandStrict) GetHelp() (string, []string) {
+	description := "Set whether using a variable that has not been assigned is an error, or evaluates to 0.  With no " +
+		"argument the current setting is shown.  It is on by default."
+	return description, []string{"strict", "strict off"}
+}
+
+func NewCommandStrict(evaluator *expreval.Evaluator) Command {
+	command := CommandStrict{}
+	command.evaluator = evaluator
+	return &command
+}
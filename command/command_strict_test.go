@@ -0,0 +1,40 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"alanmitic/gocalc/resultformatter"
+	"io"
+	"testing"
+)
+
+func TestCommandStrictOff(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	command, arguments, _ := commandParser.ParseCommand("strict off")
+	assertCommand(t, command, "strict")
+	assertArguments(t, arguments, []Argument{{expreval.TokenIdentifier, "off", 0.0, 0, 0}})
+
+	command.Execute(arguments, io.Discard)
+	if evaluator.Strict {
+		t.Error("Expected:", false, "Actual:", evaluator.Strict)
+	}
+
+	command, arguments, _ = commandParser.ParseCommand("strict on")
+	command.Execute(arguments, io.Discard)
+	if !evaluator.Strict {
+		t.Error("Expected:", true, "Actual:", evaluator.Strict)
+	}
+}
+
+func TestCommandStrictInvalidArgs(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	command, arguments, _ := commandParser.ParseCommand("strict maybe")
+
+	err := command.Execute(arguments, io.Discard)
+	if err != ErrInvalidArgs {
+		t.Error("Expected:", ErrInvalidArgs, "Actual:", err)
+	}
+}
@@ -0,0 +1,179 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"alanmitic/gocalc/resultformatter"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+const (
+	MaxTableRows = 1000
+)
+
+var ErrTooManyRows = errors.New("table has too many rows")
+
+type CommandTable struct {
+	evaluator       *expreval.Evaluator
+	resultFormatter resultformatter.ResultFormatter
+}
+
+func (commandTable *CommandTable) GetName() string {
+	return "table"
+}
+
+func (commandTable *CommandTable) GetSignatures() []Signature {
+	return []Signature{}
+}
+
+func (commandTable *CommandTable) GetMinExpressions() int {
+	// table <expression>, <variable>, <from>, <to>, <step>
+	return 5
+}
+
+func (commandTable *CommandTable) Execute(arguments []Argument, output io.Writer) error {
+	csvOutput := strings.EqualFold(arguments[len(arguments)-1].textValue, "csv")
+	if csvOutput {
+		arguments = arguments[:len(arguments)-1]
+	}
+
+	rows, err := commandTable.tabulate(arguments)
+	if err != nil {
+		return err
+	}
+
+	if csvOutput {
+		writer := csv.NewWriter(output)
+		writer.WriteAll(rows)
+		return writer.Error()
+	}
+
+	for _, line := range alignTable(rows) {
+		fmt.Fprintln(output, line)
+	}
+
+	return nil
+}
+
+func (commandTable *CommandTable) GetUsage() (string, string) {
+	return "table <expr>, ..., $var, <from>, <to>, <step> [, csv]", "Tabulate expressions of $var over a range."
+}
+
+func (commandTable *CommandTable) GetHelp() (string, []string) {
+	description := "Tabulate expressions of a variable for each step of a range, aligned and formatted using the " +
+		"current output format.  Add csv as the last argument to output comma separated values instead."
+	return description, []string{"table $x ^ 2, $x, 0, 10, 0.5", "table $x ^ 2, $x, 0, 10, 1, csv"}
+}
+
+// Evaluates the expressions for each step of the range.  The first row holds the column headings, and cells that fail
+// to evaluate are left empty, unless every cell of a column fails in which case the first error is returned.
+func (commandTable *CommandTable) tabulate(arguments []Argument) ([][]string, error) {
+	numExpressions := len(arguments) - 4
+	if numExpressions < 1 {
+		return nil, ErrInvalidArgs
+	}
+
+	variableName, err := parseSampleVariable(arguments[numExpressions])
+	if err != nil {
+		return nil, err
+	}
+
+	evaluator := commandTable.evaluator
+	defer preserveEvaluatorState(evaluator, variableName, "$ans")()
+
+	bounds := make([]float64, 3)
+	for index := range bounds {
+		bounds[index], err = evaluateSampleBound(evaluator, arguments[numExpressions+1+index])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	from, to, step := bounds[0], bounds[1], bounds[2]
+	if step == 0.0 || (to-from)/step < 0.0 {
+		return nil, ErrInvalidArgs
+	}
+
+	// Allow for rounding errors so that the end of the range is included when it is a whole number of steps.
+	numRows := int(math.Floor((to-from)/step+1e-9)) + 1
+	if numRows > MaxTableRows {
+		return nil, ErrTooManyRows
+	}
+
+	header := []string{variableName}
+	for _, argument := range arguments[:numExpressions] {
+		header = append(header, argument.textValue)
+	}
+	rows := [][]string{header}
+
+	firstErrs := make([]error, numExpressions)
+	numValid := make([]int, numExpressions)
+	resultFormatter := commandTable.resultFormatter
+	for rowIndex := 0; rowIndex < numRows; rowIndex++ {
+		x := from + step*float64(rowIndex)
+		row := []string{resultFormatter.FormatValue(x)}
+
+		for column, argument := range arguments[:numExpressions] {
+			y, err := sampleExpression(evaluator, argument.textValue, variableName, x)
+			if err != nil {
+				if firstErrs[column] == nil {
+					firstErrs[column] = err
+				}
+				row = append(row, "")
+			} else {
+				row = append(row, resultFormatter.FormatValue(y))
+				numValid[column]++
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	for column, firstErr := range firstErrs {
+		if numValid[column] == 0 && firstErr != nil {
+			return nil, arguments[column].positionError(firstErr)
+		}
+	}
+
+	return rows, nil
+}
+
+// Pads the cells so that each column is right aligned, with a rule under the column headings.
+func alignTable(rows [][]string) []string {
+	columnWidths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for column, cell := range row {
+			columnWidths[column] = maxInt(columnWidths[column], len(cell))
+		}
+	}
+
+	lines := []string{}
+	for rowIndex, row := range rows {
+		cells := []string{}
+		for column, cell := range row {
+			cells = append(cells, fmt.Sprintf("%*s", columnWidths[column], cell))
+		}
+		lines = append(lines, strings.TrimRight(strings.Join(cells, "  "), " "))
+
+		if rowIndex == 0 {
+			rules := []string{}
+			for _, columnWidth := range columnWidths {
+				rules = append(rules, strings.Repeat("-", columnWidth))
+			}
+			lines = append(lines, strings.Join(rules, "  "))
+		}
+	}
+
+	return lines
+}
+
+func NewCommandTable(evaluator *expreval.Evaluator, resultFormatter resultformatter.ResultFormatter) Command {
+	command := CommandTable{}
+	command.evaluator = evaluator
+	command.resultFormatter = resultFormatter
+	return &command
+}
@@ -0,0 +1,105 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"alanmitic/gocalc/resultformatter"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestCommandTable(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	command, arguments, _ := commandParser.ParseCommand("table $x ^ 2, 1 / $x, $x, 0, 1, 0.5")
+	assertCommand(t, command, "table")
+
+	rows, err := command.(*CommandTable).tabulate(arguments)
+	if err != nil {
+		t.Fatal("Expected:", nil, "Actual:", err)
+	}
+
+	assertTableRows(t, rows, [][]string{
+		{"$x", "$x ^ 2", "1 / $x"},
+		{"0", "0", ""},
+		{"0.5", "0.25", "2"},
+		{"1", "1", "1"}})
+
+	assertTableRows(t, [][]string{alignTable(rows)}, [][]string{{
+		" $x  $x ^ 2  1 / $x",
+		"---  ------  ------",
+		"  0       0",
+		"0.5    0.25       2",
+		"  1       1       1"}})
+}
+
+func TestCommandTableFormatted(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	resultFormatter.SetOutputMode(resultformatter.OutputModeHexadecimal)
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	command, arguments, _ := commandParser.ParseCommand("table $x * 16, $x, 1, 2, 1")
+
+	rows, _ := command.(*CommandTable).tabulate(arguments)
+	assertTableRows(t, rows, [][]string{
+		{"$x", "$x * 16"},
+		{"00000001", "00000010"},
+		{"00000002", "00000020"}})
+}
+
+func TestCommandTableInvalidArgs(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	command, _, err := commandParser.ParseCommand("table $x, $x, 0, 1")
+	assertNilCommandAndError(t, command, err, ErrInvalidArgs)
+
+	command, arguments, _ := commandParser.ParseCommand("table $x, $x, 0, 1, -1")
+	err = command.Execute(arguments, io.Discard)
+	if err != ErrInvalidArgs {
+		t.Error("Expected:", ErrInvalidArgs, "Actual:", err)
+	}
+
+	command, arguments, _ = commandParser.ParseCommand("table $x, $x, 0, 1, 0.0001, csv")
+	err = command.Execute(arguments, io.Discard)
+	if err != ErrTooManyRows {
+		t.Error("Expected:", ErrTooManyRows, "Actual:", err)
+	}
+}
+
+func TestCommandTableErrors(t *testing.T) {
+	commandParser := NewCommandParser(expreval.NewEvaluator(), resultformatter.NewResultFormatter())
+
+	// A column with no values is an error, shown at its position in the line.
+	input := "table $x ^ 2 + $y, $x, 0, 2, 1"
+	err := commandParser.GetScriptRunner().ExecuteLine(input)
+	if !errors.Is(err, expreval.ErrUndefinedVariable) {
+		t.Error("Expected:", expreval.ErrUndefinedVariable, "Actual:", err)
+	}
+
+	output := new(bytes.Buffer)
+	PrintError(output, err, input)
+	assertOutput(t, output, "ERROR: undefined variable $y at column 16, did you mean $x?\n"+
+		"  table $x ^ 2 + $y, $x, 0, 2, 1\n"+
+		"                 ^\n")
+}
+
+func assertTableRows(t *testing.T, rows [][]string, expectedRows [][]string) {
+	if len(rows) != len(expectedRows) {
+		t.Fatal("Expected:", len(expectedRows), "Actual:", len(rows))
+	}
+
+	for rowIndex, expectedRow := range expectedRows {
+		if len(rows[rowIndex]) != len(expectedRow) {
+			t.Fatal("Expected:", expectedRow, "Actual:", rows[rowIndex])
+		}
+
+		for column, expectedCell := range expectedRow {
+			if rows[rowIndex][column] != expectedCell {
+				t.Error("Expected:", expectedCell, "Actual:", rows[rowIndex][column])
+			}
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"io"
+)
+
+type CommandUnalias struct {
+	commandParser *CommandParser
+}
+
+func (commandUnalias *CommandUnalias) GetName() string {
+	return "unalias"
+}
+
+func (commandUnalias *CommandUnalias) GetSignatures() []Signature {
+	return []Signature{
+		// unalias <name>
+		[]expreval.LexAnToken{expreval.TokenIdentifier}}
+}
+
+func (commandUnalias *CommandUnalias) Execute(arguments []Argument, output io.Writer) error {
+	if len(arguments) != 1 {
+		return ErrInvalidArgs
+	}
+
+	return commandUnalias.commandParser.RemoveAlias(arguments[0].textValue)
+}
+
+func (commandUnalias *CommandUnalias) GetUsage() (string, string) {
+	return "unalias <name>", "Remove an alias."
+}
+
+func (commandUnalias *CommandUnalias) GetHelp() (string, []string) {
+	description := "Remove an alias defined with alias."
+	return description, []string{"unalias h"}
+}
+
+func NewCommandUnalias(commandParser *CommandParser) Command {
+	command := CommandUnalias{}
+	command.commandParser = commandParser
+	return &command
+}
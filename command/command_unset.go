@@ -0,0 +1,64 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"fmt"
+	"io"
+)
+
+type CommandUnset struct {
+	evaluator *expreval.Evaluator
+}
+
+func (commandUnset *CommandUnset) GetName() string {
+	return "unset"
+}
+
+func (commandUnset *CommandUnset) GetSignatures() []Signature {
+	return []Signature{
+		// unset <variable>
+		[]expreval.LexAnToken{expreval.TokenVariable}}
+}
+
+func (commandUnset *CommandUnset) IsVariadic() bool {
+	// unset <variable> ...
+	return true
+}
+
+func (commandUnset *CommandUnset) Execute(arguments []Argument, output io.Writer) error {
+	if len(arguments) == 0 {
+		return ErrInvalidArgs
+	}
+
+	// Check all the variables first so that none are removed if one is not defined.
+	for _, argument := range arguments {
+		if argument.token != expreval.TokenVariable {
+			return ErrInvalidArgs
+		}
+
+		if _, found := commandUnset.evaluator.Variables.GetVariable(argument.textValue); !found {
+			return fmt.Errorf("%w: %s", expreval.ErrUndefinedVariable, argument.textValue)
+		}
+	}
+
+	for _, argument := range arguments {
+		commandUnset.evaluator.UnsetVariable(argument.textValue)
+	}
+
+	return nil
+}
+
+func (commandUnset *CommandUnset) GetUsage() (string, string) {
+	return "unset <variable> ...", "Remove variables or constants."
+}
+
+func (commandUnset *CommandUnset) GetHelp() (string, []string) {
+	description := "Remove one or more variables or constants.  Nothing is removed if any of them is not defined."
+	return description, []string{"unset $tmp", "unset $a $b"}
+}
+
+func NewCommandUnset(evaluator *expreval.Evaluator) Command {
+	command := CommandUnset{}
+	command.evaluator = evaluator
+	return &command
+}
@@ -0,0 +1,50 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"alanmitic/gocalc/resultformatter"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestCommandUnset(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	evaluator.Variables.SetVariable("$tmp", 1)
+
+	command, arguments, _ := commandParser.ParseCommand("unset $tmp")
+	assertCommand(t, command, "unset")
+	assertArguments(t, arguments, []Argument{{expreval.TokenVariable, "$tmp", 0.0, 0, 0}})
+	err := command.Execute(arguments, io.Discard)
+	if err != nil {
+		t.Error("Expected:", nil, "Actual:", err)
+	}
+
+	if _, found := evaluator.Variables.GetVariable("$tmp"); found {
+		t.Error("Variable should not be defined: $tmp")
+	}
+
+	err = command.Execute(arguments, io.Discard)
+	if !errors.Is(err, expreval.ErrUndefinedVariable) {
+		t.Error("Expected:", expreval.ErrUndefinedVariable, "Actual:", err)
+	}
+
+	evaluator.Variables.SetVariable("$a", 1)
+	evaluator.Variables.SetVariable("$b", 2)
+	command, arguments, _ = commandParser.ParseCommand("unset $a $b $c")
+	err = command.Execute(arguments, io.Discard)
+	if !errors.Is(err, expreval.ErrUndefinedVariable) || len(evaluator.GetVariableNames()) != 2 {
+		t.Error("Expected:", expreval.ErrUndefinedVariable, "Actual:", err, evaluator.GetVariableNames())
+	}
+
+	command, arguments, _ = commandParser.ParseCommand("unset $a $b")
+	command.Execute(arguments, io.Discard)
+	if len(evaluator.GetVariableNames()) != 0 {
+		t.Error("Expected:", "no variables", "Actual:", evaluator.GetVariableNames())
+	}
+
+	command, _, err = commandParser.ParseCommand("unset $a 5")
+	assertNilCommandAndError(t, command, err, ErrInvalidArgs)
+}
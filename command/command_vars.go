@@ -2,11 +2,16 @@ package command
 
 import (
 	"alanmitic/gocalc/expreval"
+	"alanmitic/gocalc/resultformatter"
 	"fmt"
+	"io"
+	"path"
+	"strings"
 )
 
 type CommandVars struct {
-	evaluator *expreval.Evaluator
+	evaluator       *expreval.Evaluator
+	resultFormatter resultformatter.ResultFormatter
 }
 
 func (commandVar *CommandVars) GetName() string {
@@ -14,31 +19,74 @@ func (commandVar *CommandVars) GetName() string {
 }
 
 func (commandVar *CommandVars) GetSignatures() []Signature {
-	return []Signature{
-		// vars
-		[]expreval.LexAnToken{}}
+	return []Signature{}
 }
 
-func (commandVar *CommandVars) Execute(arguments []Argument) error {
-	variables := commandVar.evaluator.VariableStore
-	if len(variables) > 0 {
-		fmt.Println("Variables:")
-		for variableName, variable := range variables {
-			fmt.Println(variableName, " => ", variable)
+func (commandVar *CommandVars) IsTextRequired() bool {
+	// vars [pattern]
+	return false
+}
+
+func (commandVar *CommandVars) Execute(arguments []Argument, output io.Writer) error {
+	// The pattern is a glob, e.g. "$r*", with the leading "$" optional.
+	pattern := "*"
+	if len(arguments) == 1 {
+		pattern = arguments[0].textValue
+	}
+
+	if !strings.HasPrefix(pattern, "$") {
+		pattern = "$" + pattern
+	}
+
+	variableNames := []string{}
+	for _, variableName := range commandVar.evaluator.GetVariableNames() {
+		matched, err := path.Match(pattern, variableName)
+		if err != nil {
+			return ErrInvalidArgs
+		}
+
+		if matched {
+			variableNames = append(variableNames, variableName)
+		}
+	}
+
+	if len(variableNames) == 0 {
+		if len(arguments) == 1 {
+			fmt.Fprintln(output, "No variables match", arguments[0].textValue)
+		} else {
+			fmt.Fprintln(output, "No variables defined!")
+		}
+		return nil
+	}
+
+	fmt.Fprintln(output, "Variables:")
+	for _, variableName := range variableNames {
+		variableValue, _ := commandVar.evaluator.Variables.GetVariable(variableName)
+		value := commandVar.resultFormatter.FormatValue(variableValue)
+		if commandVar.evaluator.IsConstant(variableName) {
+			fmt.Fprintf(output, "%s => %s (const)\n", variableName, value)
+		} else {
+			fmt.Fprintf(output, "%s => %s\n", variableName, value)
 		}
-	} else {
-		fmt.Println("No variables defined!")
 	}
 
 	return nil
 }
 
 func (commandVar *CommandVars) GetUsage() (string, string) {
-	return "vars", "List defined variables."
+	return "vars <pattern>", "List defined variables, optionally only those matching a pattern, e.g. vars $r*."
+}
+
+func (commandVar *CommandVars) GetHelp() (string, []string) {
+	description := "List the variables in name order with their values formatted using the current output format.  A " +
+		"glob pattern, where * matches any characters and ? a single character, lists only the matching " +
+		"variables.  The $ at the start of the pattern is optional."
+	return description, []string{"vars", "vars $r*"}
 }
 
-func NewCommandVars(evaluator *expreval.Evaluator) Command {
+func NewCommandVars(evaluator *expreval.Evaluator, resultFormatter resultformatter.ResultFormatter) Command {
 	command := CommandVars{}
 	command.evaluator = evaluator
+	command.resultFormatter = resultFormatter
 	return &command
 }
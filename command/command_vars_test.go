@@ -3,6 +3,7 @@ package command
 import (
 	"alanmitic/gocalc/expreval"
 	"alanmitic/gocalc/resultformatter"
+	"bytes"
 	"testing"
 )
 
@@ -13,5 +14,48 @@ func TestCommandVars(t *testing.T) {
 	command, arguments, _ := commandParser.ParseCommand("vars")
 	assertCommand(t, command, "vars")
 	assertArguments(t, arguments, []Argument{})
-	// This just outputs to stdout. No specific test.
+
+	output := new(bytes.Buffer)
+	command.Execute(arguments, output)
+	assertOutput(t, output, "No variables defined!\n")
+
+	evaluator.Variables.SetVariable("$b", 2.5)
+	evaluator.Variables.SetVariable("$a", 2)
+	evaluator.SetConstant("$g", 9.80665)
+	resultFormatter.SetOutputMode(resultformatter.OutputModeFixed)
+	resultFormatter.SetPrecision(2)
+	output.Reset()
+	command.Execute(arguments, output)
+	assertOutput(t, output, "Variables:\n$a => 2.00\n$b => 2.50\n$g => 9.81 (const)\n")
+}
+
+func TestCommandVarsPattern(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	evaluator.Variables.SetVariable("$rate", 5)
+	evaluator.Variables.SetVariable("$ratio", 2)
+	evaluator.Variables.SetVariable("$tax", 1)
+
+	command, arguments, _ := commandParser.ParseCommand("vars $rat*")
+	assertArguments(t, arguments, []Argument{{expreval.TokenEnd, "$rat*", 0.0, 0, 0}})
+	output := new(bytes.Buffer)
+	command.Execute(arguments, output)
+	assertOutput(t, output, "Variables:\n$rate => 5\n$ratio => 2\n")
+
+	command, arguments, _ = commandParser.ParseCommand("vars t?x")
+	output.Reset()
+	command.Execute(arguments, output)
+	assertOutput(t, output, "Variables:\n$tax => 1\n")
+
+	command, arguments, _ = commandParser.ParseCommand("vars $x*")
+	output.Reset()
+	command.Execute(arguments, output)
+	assertOutput(t, output, "No variables match $x*\n")
+
+	command, arguments, _ = commandParser.ParseCommand("vars [")
+	err := command.Execute(arguments, output)
+	if err != ErrInvalidArgs {
+		t.Error("Expected:", ErrInvalidArgs, "Actual:", err)
+	}
 }
@@ -0,0 +1,123 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Completes command names, command arguments, variables, literals, functions and in RPN mode stack operations, e.g.
+// for a line editor.
+type Completer struct {
+	commandParser *CommandParser
+	evaluator     *expreval.Evaluator
+}
+
+func NewCompleter(commandParser *CommandParser, evaluator *expreval.Evaluator) *Completer {
+	completer := Completer{}
+	completer.commandParser = commandParser
+	completer.evaluator = evaluator
+	return &completer
+}
+
+// Completes the word at the end of the text.  Returns the index of the start of the word and the sorted names that
+// start with it.
+func (completer *Completer) Complete(text string) (int, []string) {
+	start := strings.LastIndexFunc(text, func(c rune) bool {
+		return !isNameCharacter(c)
+	}) + 1
+	word := text[start:]
+
+	// A "$" can only start a variable name.
+	if strings.Contains(strings.TrimPrefix(word, "$"), "$") {
+		return start, nil
+	}
+
+	if strings.HasPrefix(word, "$") {
+		return start, filterNames(completer.evaluator.GetVariableNames(), word)
+	}
+
+	names := append(expreval.GetLiterals(), completer.evaluator.GetFunctionNames()...)
+	if completer.commandParser.scriptRunner.RPN {
+		names = append(names, expreval.GetStackOperations()...)
+	}
+
+	if len(strings.TrimSpace(text[:start])) == 0 {
+		// The first word is a command or the start of an expression.
+		for commandName := range completer.commandParser.commands {
+			names = append(names, commandName)
+		}
+		for aliasName := range completer.commandParser.aliases {
+			names = append(names, aliasName)
+		}
+	} else if command := completer.findCommand(text[:start]); command != nil {
+		names = completeArgument(command, text[:start])
+	}
+
+	return start, filterNames(names, word)
+}
+
+// Finds the command at the start of the text, or nil if the text is an expression.
+func (completer *Completer) findCommand(text string) Command {
+	lexAn := expreval.CreateLexicalAnalyser(text)
+	if lexAn.ParseNextToken() != expreval.TokenIdentifier || expreval.IsLiteral(lexAn.GetTextValue()) ||
+		completer.evaluator.IsFunction(lexAn.GetTextValue()) {
+		return nil
+	}
+	command, _ := completer.commandParser.lookupCommand(lexAn.GetTextValue())
+	return command
+}
+
+// Gets the names that may be the next argument of the command.  The text is the command and the arguments before the
+// one being completed.  Identifier arguments are completed from the command's keywords, and the expressions of
+// expression list commands from the literals.
+func completeArgument(command Command, text string) []string {
+	switch command.(type) {
+	case TextCommand:
+		return nil
+	case ExpressionListCommand:
+		return expreval.GetLiterals()
+	}
+
+	keywordCommand, ok := command.(KeywordCommand)
+	if !ok {
+		return nil
+	}
+
+	// Count the arguments before the one being completed.
+	lexAn := expreval.CreateLexicalAnalyser(text)
+	lexAn.ParseNextToken()
+	position := 0
+	for token := lexAn.ParseNextToken(); token != expreval.TokenEnd; token = lexAn.ParseNextToken() {
+		if token == expreval.TokenBad {
+			return nil
+		}
+		position++
+	}
+
+	for _, signature := range command.GetSignatures() {
+		if position < len(signature) && signature[position] == expreval.TokenIdentifier {
+			return keywordCommand.GetKeywords()
+		}
+	}
+
+	return nil
+}
+
+// Gets the distinct names that start with the prefix, sorted.
+func filterNames(names []string, prefix string) []string {
+	sort.Strings(names)
+
+	filtered := []string{}
+	for index, name := range names {
+		if strings.HasPrefix(name, prefix) && (index == 0 || name != names[index-1]) {
+			filtered = append(filtered, name)
+		}
+	}
+	return filtered
+}
+
+func isNameCharacter(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c) || c == '$' || c == '_'
+}
@@ -0,0 +1,70 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"alanmitic/gocalc/resultformatter"
+	"reflect"
+	"testing"
+)
+
+func TestCompleterCommands(t *testing.T) {
+	completer := createCompleter()
+
+	assertCompletion(t, completer, "he", 0, []string{"help", "hex"})
+	assertCompletion(t, completer, "  so", 2, []string{"source"})
+	assertCompletion(t, completer, "n", 0, []string{"nan", "nonfinite"})
+	assertCompletion(t, completer, "xyz", 0, []string{})
+}
+
+func TestCompleterVariables(t *testing.T) {
+	completer := createCompleter()
+
+	assertCompletion(t, completer, "$", 0, []string{"$rate", "$tax", "$total"})
+	assertCompletion(t, completer, "1 + $t", 4, []string{"$tax", "$total"})
+	assertCompletion(t, completer, "($ra", 1, []string{"$rate"})
+	assertCompletion(t, completer, "plot $ra", 5, []string{"$rate"})
+	assertCompletion(t, completer, "$ta$", 0, nil)
+}
+
+func TestCompleterArguments(t *testing.T) {
+	completer := createCompleter()
+
+	// Keywords from the signatures, and no completion past the last argument.
+	assertCompletion(t, completer, "strict ", 7, []string{"off", "on"})
+	assertCompletion(t, completer, "nonfinite p", 10, []string{"propagate"})
+	assertCompletion(t, completer, "onerror stop s", 13, []string{})
+
+	// Literals in expressions, and nothing for commands taking numbers or text.
+	assertCompletion(t, completer, "1 + i", 4, []string{"inf"})
+	assertCompletion(t, completer, "table i", 6, []string{"inf"})
+	assertCompletion(t, completer, "fix ", 4, []string{})
+	assertCompletion(t, completer, "source f", 7, []string{})
+}
+
+func TestCompleterRPN(t *testing.T) {
+	completer := createCompleter()
+	completer.commandParser.GetScriptRunner().RPN = true
+
+	assertCompletion(t, completer, "d", 0, []string{"drop", "dup"})
+	assertCompletion(t, completer, "1 2 sw", 4, []string{"swap"})
+}
+
+func createCompleter() *Completer {
+	evaluator := expreval.NewEvaluator()
+	evaluator.Variables.SetVariable("$rate", 5)
+	evaluator.Variables.SetVariable("$tax", 2)
+	evaluator.Variables.SetVariable("$total", 7)
+	commandParser := NewCommandParser(evaluator, resultformatter.NewResultFormatter())
+	return NewCompleter(commandParser, evaluator)
+}
+
+func assertCompletion(t *testing.T, completer *Completer, text string, expectedStart int, expectedCandidates []string) {
+	start, candidates := completer.Complete(text)
+	if start != expectedStart {
+		t.Errorf("Text: %q Expected start: %d Actual: %d", text, expectedStart, start)
+	}
+
+	if !reflect.DeepEqual(candidates, expectedCandidates) {
+		t.Errorf("Text: %q Expected: %q Actual: %q", text, expectedCandidates, candidates)
+	}
+}
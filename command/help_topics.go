@@ -0,0 +1,47 @@
+package command
+
+// Help topics, shown by "help <topic>".
+var helpTopics = map[string]string{
+	"operators": `Operators, from the lowest to the highest precedence:
+
+  + -    Addition and subtraction       1 + 2
+  * /    Multiplication and division    10 / 20
+  ^      Power                          2 ^ 4
+  %      Percent                        200 * 15%
+  ( )    Parentheses                    2 * (1 + (3 / 4))
+  =      Assign a variable              $a = 5
+
+As on a calculator, adding or subtracting a percentage is relative to the left term, so 200 + 10% is 220.
+Input starting with an operator other than minus continues from the previous result, e.g. * 2 doubles $ans,
+while -5 is still negative five.  continue off turns this off.`,
+
+	"bases": `Numbers can be input in binary, octal, decimal or hexadecimal:
+
+  b$1010        Binary
+  o$777         Octal
+  1234.5678     Decimal, the default
+  h$1234ABCD    Hexadecimal
+
+Results are output in binary, octal or hexadecimal with the bin, oct and hex commands, as the lower 32 bits of
+the integer part of the value, and in decimal with the fix, real and sci commands.`,
+
+	"functions": `Functions are called with their arguments in parentheses, e.g. sin(pi / 2), and in RPN mode use the
+values at the top of the stack, e.g. 2 sqrt.  Angles are in radians.  A program that embeds the calculator can
+register more functions, and the functions are listed below.  The literals are:
+
+  inf    Infinity, e.g. -inf
+  nan    Not a number
+  pi     The ratio of a circle's circumference to its diameter
+
+By default an operation on finite values that overflows, such as 10 ^ 400, or has an undefined result is an
+error naming the operation.  nonfinite propagate makes the infinite or NaN value the result instead.`,
+
+	"variables": `Variables are a $ followed by a letter and then letters or digits, e.g. $rate, and are assigned with =:
+
+  $rate = 5
+  $rate * 2
+
+$ans holds the latest result, and earlier results are $_n or $ans[n], or $ans[-n] counting back from the
+latest.  Using a variable that has not been assigned is an error, unless strict is off.  Variables are
+managed with the vars, const, unset, rename and clear vars commands.`,
+}
@@ -0,0 +1,102 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var ErrMacroNotEnded = errors.New("macro has no end")
+var ErrNestedMacro = errors.New("macros cannot be defined in a macro")
+
+// Positional arguments in the lines of a macro, e.g. "$1".
+var macroArgumentPattern = regexp.MustCompile(`\$[0-9]+`)
+
+// Error in a line of a macro, giving the macro and the line where it occurred.  The line is kept, with the arguments
+// replaced, so that the position of an evaluation error can be shown in it.
+type MacroError struct {
+	Name string
+	Line int
+	Text string
+	Err  error
+}
+
+func (macroError *MacroError) Error() string {
+	return fmt.Sprintf("%s:%d: %v", macroError.Name, macroError.Line, macroError.Err)
+}
+
+func (macroError *MacroError) Unwrap() error {
+	return macroError.Err
+}
+
+// A sequence of command and expression lines recorded by the macro command, which are run when the macro's name is
+// used as a command.  The arguments are a comma separated list of expressions that replace $1, $2 and so on in the
+// lines.
+type Macro struct {
+	name         string
+	lines        []string
+	scriptRunner *ScriptRunner
+}
+
+func (macro *Macro) GetName() string {
+	return macro.name
+}
+
+func (macro *Macro) GetSignatures() []Signature {
+	return []Signature{}
+}
+
+func (macro *Macro) GetMinExpressions() int {
+	return macro.getArgumentCount()
+}
+
+func (macro *Macro) Execute(arguments []Argument, output io.Writer) error {
+	if len(arguments) > macro.getArgumentCount() {
+		return ErrTooManyArgs
+	}
+
+	return macro.scriptRunner.runWithOutput(output, func() error {
+		return macro.scriptRunner.runMacro(macro, arguments)
+	})
+}
+
+// Gets the number of arguments used by the lines, i.e. the highest argument number.
+func (macro *Macro) getArgumentCount() int {
+	count := 0
+	for _, line := range macro.lines {
+		for _, argument := range macroArgumentPattern.FindAllString(line, -1) {
+			number, _ := strconv.Atoi(argument[1:])
+			count = maxInt(count, number)
+		}
+	}
+	return count
+}
+
+func (macro *Macro) GetUsage() (string, string) {
+	parameters := []string{}
+	for number := 1; number <= macro.getArgumentCount(); number++ {
+		parameters = append(parameters, fmt.Sprintf("<$%d>", number))
+	}
+
+	usageSyntax := strings.TrimSpace(macro.name + " " + strings.Join(parameters, ", "))
+	return usageSyntax, "Macro: " + strings.Join(macro.lines, "; ")
+}
+
+func (macro *Macro) GetHelp() (string, []string) {
+	description := fmt.Sprintf("Macro recorded with macro %s, which runs the lines:", macro.name)
+	return description, macro.lines
+}
+
+// Replaces the positional arguments in the line by the argument expressions, in parentheses.
+func expandMacroArguments(line string, arguments []Argument) string {
+	return macroArgumentPattern.ReplaceAllStringFunc(line, func(argument string) string {
+		number, _ := strconv.Atoi(argument[1:])
+		if number < 1 || number > len(arguments) {
+			return argument
+		}
+		return "(" + arguments[number-1].textValue + ")"
+	})
+}
@@ -0,0 +1,72 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"math"
+)
+
+// Extracts the variable name from an argument that must consist of a single variable, e.g. "$x".
+func parseSampleVariable(argument Argument) (string, error) {
+	lexAn := expreval.CreateLexicalAnalyser(argument.textValue)
+	if lexAn.ParseNextToken() != expreval.TokenVariable {
+		return "", ErrInvalidArgs
+	}
+
+	variableName := lexAn.GetTextValue()
+	if lexAn.ParseNextToken() != expreval.TokenEnd {
+		return "", ErrInvalidArgs
+	}
+
+	return variableName, nil
+}
+
+// Evaluates an argument that holds a single finite value, e.g. a range bound or a step.
+func evaluateSampleBound(evaluator *expreval.Evaluator, argument Argument) (float64, error) {
+	value, err := evaluator.Evaluate(argument.textValue)
+	if err != nil {
+		return 0.0, argument.positionError(err)
+	}
+
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0.0, ErrInvalidArgs
+	}
+
+	return value, nil
+}
+
+// Evaluates the expression with the variable set to the supplied value.
+func sampleExpression(evaluator *expreval.Evaluator, expression string, variableName string,
+	value float64) (float64, error) {
+	if err := evaluator.Variables.SetVariable(variableName, value); err != nil {
+		return 0.0, err
+	}
+	return evaluator.Evaluate(expression)
+}
+
+// Records the current state of the named variables, the history and the continuation setting and returns a function
+// that restores it.  Sampling does not leave the sample variable, $ans or history entries behind, and the sampled
+// expressions do not continue from $ans.
+func preserveEvaluatorState(evaluator *expreval.Evaluator, variableNames ...string) func() {
+	history := evaluator.History
+	continueFromAns := evaluator.ContinueFromAns
+	evaluator.ContinueFromAns = false
+
+	values := make(map[string]float64)
+	for _, variableName := range variableNames {
+		if value, found := evaluator.Variables.GetVariable(variableName); found {
+			values[variableName] = value
+		}
+	}
+
+	return func() {
+		evaluator.History = history
+		evaluator.ContinueFromAns = continueFromAns
+		for _, variableName := range variableNames {
+			if value, found := values[variableName]; found {
+				evaluator.Variables.SetVariable(variableName, value)
+			} else {
+				evaluator.Variables.DeleteVariable(variableName)
+			}
+		}
+	}
+}
@@ -0,0 +1,480 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"alanmitic/gocalc/resultformatter"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+const (
+	// Maximum depth of scripts sourcing other scripts.
+	MaxSourceDepth = 16
+	// Maximum length of a line of a script in bytes.
+	MaxScriptLineLength = 1024 * 1024
+	// Prompt for interactive input until it is changed by the prompt command.
+	DefaultPrompt = "gocalc >> "
+	// Prompt for the lines of a macro being recorded.
+	MacroPrompt = "... "
+)
+
+var ErrScriptFailed = errors.New("script failed")
+var ErrSourceDepth = errors.New("scripts are nested too deeply")
+var ErrInterrupted = errors.New("interrupted")
+
+// Error in a script, giving the file and line where it occurred.
+type ScriptError struct {
+	FileName string
+	Line     int
+	Err      error
+}
+
+func (scriptError *ScriptError) Error() string {
+	return fmt.Sprintf("%s:%d: %v", scriptError.FileName, scriptError.Line, scriptError.Err)
+}
+
+func (scriptError *ScriptError) Unwrap() error {
+	return scriptError.Err
+}
+
+// Error executing a line recalled from the history, e.g. by "!3".  The recalled line is kept so that the position of
+// an evaluation error can be shown in it.
+type RecallError struct {
+	Line string
+	Err  error
+}
+
+func (recallError *RecallError) Error() string {
+	return recallError.Err.Error()
+}
+
+func (recallError *RecallError) Unwrap() error {
+	return recallError.Err
+}
+
+// Error executing a line that starts with an alias.  The line with the alias replaced by its command line is kept, as
+// the position of an evaluation error is within it.
+type AliasError struct {
+	Line string
+	Err  error
+}
+
+func (aliasError *AliasError) Error() string {
+	return aliasError.Err.Error()
+}
+
+func (aliasError *AliasError) Unwrap() error {
+	return aliasError.Err
+}
+
+// Runs lines of input through the command parser and evaluator, the same as if they were typed at the prompt.
+type ScriptRunner struct {
+	commandParser   *CommandParser
+	evaluator       *expreval.Evaluator
+	resultFormatter resultformatter.ResultFormatter
+	// When set, a script stops at the first line that fails rather than continuing with the next line.
+	StopOnError bool
+	// Where results and the output of commands are written.
+	Output io.Writer
+	// Where errors in a script are printed.
+	ErrorOutput io.Writer
+	// When set, results are printed with their number in the history, e.g. "[3] 42", and lines recalled from the
+	// history are echoed.
+	NumberResults bool
+	// Prompt for interactive input.
+	Prompt string
+	// When set, lines that are not commands are reverse Polish input for the RPN stack.
+	RPN      bool
+	rpnStack *expreval.RPNStack
+	// Macro whose lines are being recorded, if any.
+	macro       *Macro
+	depth       int
+	interrupted atomic.Bool
+}
+
+func NewScriptRunner(commandParser *CommandParser, evaluator *expreval.Evaluator,
+	resultFormatter resultformatter.ResultFormatter) *ScriptRunner {
+	scriptRunner := ScriptRunner{}
+	scriptRunner.commandParser = commandParser
+	scriptRunner.evaluator = evaluator
+	scriptRunner.resultFormatter = resultFormatter
+	scriptRunner.rpnStack = expreval.NewRPNStack(evaluator)
+	scriptRunner.Prompt = DefaultPrompt
+	scriptRunner.Output = os.Stdout
+	scriptRunner.ErrorOutput = os.Stdout
+	return &scriptRunner
+}
+
+// Executes a line as a command, or otherwise evaluates it as an expression and prints the result.  A line of "!3"
+// evaluates the expression of history entry 3 again, and "!!" the latest entry.
+func (scriptRunner *ScriptRunner) ExecuteLine(line string) error {
+	if scriptRunner.macro != nil {
+		return scriptRunner.recordMacroLine(line)
+	}
+
+	trimmedLine := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmedLine, "!") {
+		return scriptRunner.executeLine(line)
+	}
+
+	recalledLine, err := scriptRunner.recallLine(trimmedLine[1:])
+	if err != nil {
+		return err
+	}
+
+	if scriptRunner.NumberResults {
+		fmt.Fprintln(scriptRunner.Output, recalledLine)
+	}
+
+	if err := scriptRunner.executeLine(recalledLine); err != nil {
+		return &RecallError{recalledLine, err}
+	}
+
+	return nil
+}
+
+// Gets the expression of the history entry referenced by the text after the "!".
+func (scriptRunner *ScriptRunner) recallLine(reference string) (string, error) {
+	number := -1
+	if reference != "!" {
+		var err error
+		number, err = strconv.Atoi(reference)
+		if err != nil {
+			return "", fmt.Errorf("%w: !%s", expreval.ErrHistoryNotFound, reference)
+		}
+	}
+
+	entry, found := scriptRunner.evaluator.GetHistoryEntry(number)
+	if !found || number == 0 {
+		return "", fmt.Errorf("%w: !%s", expreval.ErrHistoryNotFound, reference)
+	}
+
+	return entry.Expression, nil
+}
+
+// Starts recording the lines of a macro, up to a line of "end".
+func (scriptRunner *ScriptRunner) startMacro(name string) error {
+	if err := scriptRunner.commandParser.checkName(name); err != nil {
+		return err
+	}
+
+	if _, found := scriptRunner.commandParser.aliases[name]; found {
+		return fmt.Errorf("%w: %s is an alias", ErrNameConflict, name)
+	}
+
+	scriptRunner.macro = &Macro{name, []string{}, scriptRunner}
+	return nil
+}
+
+// Records a line of the macro, or adds the macro at the end of it.
+func (scriptRunner *ScriptRunner) recordMacroLine(line string) error {
+	trimmedLine := strings.TrimSpace(line)
+	if len(trimmedLine) == 0 {
+		return nil
+	}
+
+	if trimmedLine == "end" {
+		macro := scriptRunner.macro
+		scriptRunner.macro = nil
+		return scriptRunner.commandParser.addMacro(macro)
+	}
+
+	lexAn := expreval.CreateLexicalAnalyser(trimmedLine)
+	if lexAn.ParseNextToken() == expreval.TokenIdentifier && lexAn.GetTextValue() == "macro" {
+		return ErrNestedMacro
+	}
+
+	scriptRunner.macro.lines = append(scriptRunner.macro.lines, trimmedLine)
+	return nil
+}
+
+// Reports whether the lines of a macro are being recorded.
+func (scriptRunner *ScriptRunner) IsRecordingMacro() bool {
+	return scriptRunner.macro != nil
+}
+
+// Abandons the macro being recorded, if any.
+func (scriptRunner *ScriptRunner) CancelMacro() {
+	scriptRunner.macro = nil
+}
+
+// Runs the lines of the macro with the arguments, stopping at the first line that fails.
+func (scriptRunner *ScriptRunner) runMacro(macro *Macro, arguments []Argument) error {
+	if scriptRunner.depth >= MaxSourceDepth {
+		return ErrSourceDepth
+	}
+
+	scriptRunner.depth++
+	defer func() {
+		scriptRunner.depth--
+	}()
+
+	for index, line := range macro.lines {
+		if scriptRunner.interrupted.Load() {
+			return ErrInterrupted
+		}
+
+		expandedLine := expandMacroArguments(line, arguments)
+		err := scriptRunner.ExecuteLine(expandedLine)
+		if errors.Is(err, ErrExit) || errors.Is(err, ErrInterrupted) {
+			return err
+		}
+
+		if err != nil {
+			return &MacroError{macro.name, index + 1, expandedLine, err}
+		}
+	}
+
+	return nil
+}
+
+// Runs the function with results and the output of commands written to the writer, e.g. the output given to a command
+// that runs other lines.
+func (scriptRunner *ScriptRunner) runWithOutput(output io.Writer, run func() error) error {
+	previousOutput := scriptRunner.Output
+	scriptRunner.Output = output
+	defer func() {
+		scriptRunner.Output = previousOutput
+	}()
+
+	return run()
+}
+
+// Gets the stack used in RPN mode.
+func (scriptRunner *ScriptRunner) GetRPNStack() *expreval.RPNStack {
+	return scriptRunner.rpnStack
+}
+
+// Prints the values on the RPN stack, numbered by their level with the top of the stack last as level 1.
+func (scriptRunner *ScriptRunner) PrintRPNStack() {
+	values := scriptRunner.rpnStack.GetValues()
+	if len(values) == 0 {
+		fmt.Fprintln(scriptRunner.Output, "Stack empty")
+	}
+
+	for index, value := range values {
+		fmt.Fprintf(scriptRunner.Output, "%d: %s\n", len(values)-index, scriptRunner.resultFormatter.FormatValue(value))
+	}
+}
+
+func (scriptRunner *ScriptRunner) executeLine(line string) error {
+	if scriptRunner.RPN && scriptRunner.isRPNInput(line) {
+		err := scriptRunner.rpnStack.Evaluate(line)
+		if err != nil {
+			return err
+		}
+
+		scriptRunner.PrintRPNStack()
+		return nil
+	}
+
+	if expandedLine, expanded := scriptRunner.commandParser.expandAlias(line); expanded {
+		if err := scriptRunner.executeCommandLine(expandedLine); err != nil {
+			return &AliasError{expandedLine, err}
+		}
+		return nil
+	}
+
+	return scriptRunner.executeCommandLine(line)
+}
+
+// Executes a line as a command, or otherwise evaluates it as an expression and prints the result.
+func (scriptRunner *ScriptRunner) executeCommandLine(line string) error {
+	cmd, arguments, err := scriptRunner.commandParser.ParseCommand(line)
+	if err != nil {
+		return err
+	}
+
+	if cmd != nil {
+		err := cmd.Execute(arguments, scriptRunner.Output)
+		if err != nil {
+			return newUsageError(cmd, err)
+		}
+		return nil
+	}
+
+	result, err := scriptRunner.evaluator.Evaluate(line)
+	if err != nil {
+		return err
+	}
+
+	history := scriptRunner.evaluator.History
+	if scriptRunner.NumberResults && len(history) > 0 {
+		fmt.Fprintf(scriptRunner.Output, "[%d] ", history[len(history)-1].Number)
+	}
+
+	fmt.Fprintln(scriptRunner.Output, scriptRunner.resultFormatter.FormatValue(result))
+	return nil
+}
+
+// Reports whether a line in RPN mode is input for the stack rather than a command.  Stack operations take precedence
+// over commands with the same name, unless the command's keyword follows.
+func (scriptRunner *ScriptRunner) isRPNInput(line string) bool {
+	lexAn := expreval.CreateLexicalAnalyser(line)
+	if lexAn.ParseNextToken() != expreval.TokenIdentifier {
+		return true
+	}
+
+	name := lexAn.GetTextValue()
+	if scriptRunner.evaluator.IsFunction(name) {
+		return true
+	}
+
+	if !expreval.IsStackOperation(name) {
+		_, err := scriptRunner.commandParser.lookupCommand(name)
+		return errors.Is(err, ErrNotFound)
+	}
+
+	// A stack operation that is also a command, such as clear, is the command when followed by one of its keywords,
+	// e.g. "clear vars".
+	command := scriptRunner.commandParser.commands[name]
+	if keywordCommand, ok := command.(KeywordCommand); ok && lexAn.ParseNextToken() == expreval.TokenIdentifier {
+		for _, keyword := range keywordCommand.GetKeywords() {
+			if keyword == lexAn.GetTextValue() {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Requests that any running script stops before its next line.  This is safe to call from another goroutine, such as
+// a signal handler.
+func (scriptRunner *ScriptRunner) Interrupt() {
+	scriptRunner.interrupted.Store(true)
+}
+
+// Clears any earlier interrupt request, ready to run the next line of input.
+func (scriptRunner *ScriptRunner) ClearInterrupt() {
+	scriptRunner.interrupted.Store(false)
+}
+
+// Runs the script in the named file.
+func (scriptRunner *ScriptRunner) RunFile(fileName string) error {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return scriptRunner.Run(file, fileName)
+}
+
+// Runs each line of the script.  Blank lines and lines starting with '#', such as a "#!" line, are ignored.  Errors are
+// printed with the file name and line number as they occur, and ErrScriptFailed is returned if any line failed.  An
+// exit command or an interrupt stops the script and its error is returned.
+func (scriptRunner *ScriptRunner) Run(reader io.Reader, fileName string) error {
+	if scriptRunner.depth >= MaxSourceDepth {
+		return ErrSourceDepth
+	}
+
+	scriptRunner.depth++
+	defer func() {
+		scriptRunner.depth--
+	}()
+
+	// A macro started in the script must end in it.
+	recordingMacro := scriptRunner.macro != nil
+	defer func() {
+		if !recordingMacro {
+			scriptRunner.macro = nil
+		}
+	}()
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, MaxScriptLineLength)
+	lineNumber := 0
+	failed := false
+
+	for scanner.Scan() {
+		if scriptRunner.interrupted.Load() {
+			return ErrInterrupted
+		}
+
+		lineNumber++
+		line := scanner.Text()
+
+		trimmedLine := strings.TrimSpace(line)
+		if len(trimmedLine) == 0 || strings.HasPrefix(trimmedLine, "#") {
+			continue
+		}
+
+		err := scriptRunner.ExecuteLine(line)
+		if errors.Is(err, ErrExit) || errors.Is(err, ErrInterrupted) {
+			return err
+		}
+
+		if err != nil {
+			PrintError(scriptRunner.ErrorOutput, &ScriptError{fileName, lineNumber, err}, line)
+			failed = true
+
+			if scriptRunner.StopOnError {
+				break
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if !recordingMacro && scriptRunner.macro != nil {
+		err := fmt.Errorf("%w: %s", ErrMacroNotEnded, scriptRunner.macro.name)
+		PrintError(scriptRunner.ErrorOutput, &ScriptError{fileName, lineNumber, err}, "")
+		failed = true
+	}
+
+	if failed {
+		return ErrScriptFailed
+	}
+
+	return nil
+}
+
+// Prints the error, followed by the input with a caret under the column where an evaluation error was found.
+func PrintError(writer io.Writer, err error, input string) {
+	fmt.Fprintln(writer, "ERROR:", err)
+
+	var evaluationError *expreval.EvaluationError
+	if !errors.As(err, &evaluationError) {
+		return
+	}
+
+	// The position is within the line of a macro, the line recalled from the history or the line an alias was replaced
+	// by, rather than the input.  These lines may be nested, e.g. a macro running another macro, so the innermost line
+	// is used.
+	for wrappedErr := err; wrappedErr != nil; wrappedErr = errors.Unwrap(wrappedErr) {
+		switch lineError := wrappedErr.(type) {
+		case *MacroError:
+			input = lineError.Text
+		case *RecallError:
+			input = lineError.Line
+		case *AliasError:
+			input = lineError.Line
+		}
+	}
+
+	// Keep any tabs in the padding so that the caret lines up with the input.
+	input = strings.TrimRight(input, "\r\n")
+	if evaluationError.Start > len(input) {
+		return
+	}
+
+	padding := strings.Map(func(c rune) rune {
+		if unicode.IsSpace(c) {
+			return c
+		}
+		return ' '
+	}, input[:evaluationError.Start])
+
+	fmt.Fprintln(writer, "  "+input)
+	fmt.Fprintln(writer, "  "+padding+"^")
+}
@@ -0,0 +1,186 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"alanmitic/gocalc/resultformatter"
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScriptRunnerRun(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	scriptRunner := commandParser.GetScriptRunner()
+
+	script := "#!/usr/bin/env gocalc\n# Comment\n\nfix 4\n$a = 2\n$b = $a * 3\n"
+	err := scriptRunner.Run(strings.NewReader(script), "test.gc")
+	if err != nil {
+		t.Error("Expected:", nil, "Actual:", err)
+	}
+
+	assertOutputModeAndPrecision(t, resultFormatter, resultformatter.OutputModeFixed, 4)
+	assertScriptVariable(t, evaluator, "$b", 6)
+}
+
+func TestScriptRunnerLongLine(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	scriptRunner := commandParser.GetScriptRunner()
+	scriptRunner.Output = io.Discard
+
+	// A line longer than the default buffer of a scanner is read in full.
+	script := "$a = " + strings.Repeat("1 + ", 25000) + "1\n"
+	err := scriptRunner.Run(strings.NewReader(script), "test.gc")
+	if err != nil {
+		t.Error("Expected:", nil, "Actual:", err)
+	}
+	assertScriptVariable(t, evaluator, "$a", 25001)
+
+	err = scriptRunner.Run(strings.NewReader(strings.Repeat(" ", MaxScriptLineLength+1)), "test.gc")
+	if !errors.Is(err, bufio.ErrTooLong) {
+		t.Error("Expected:", bufio.ErrTooLong, "Actual:", err)
+	}
+}
+
+func TestScriptRunnerContinueOnError(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	scriptRunner := commandParser.GetScriptRunner()
+
+	err := scriptRunner.Run(strings.NewReader("$a = 1 +\n$b = 2\n"), "test.gc")
+	if err != ErrScriptFailed {
+		t.Error("Expected:", ErrScriptFailed, "Actual:", err)
+	}
+
+	assertScriptVariable(t, evaluator, "$b", 2)
+}
+
+func TestScriptRunnerStopOnError(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	scriptRunner := commandParser.GetScriptRunner()
+
+	err := scriptRunner.Run(strings.NewReader("onerror stop\n$a = 1 +\n$b = 2\n"), "test.gc")
+	if err != ErrScriptFailed {
+		t.Error("Expected:", ErrScriptFailed, "Actual:", err)
+	}
+
+	if _, found := evaluator.Variables.GetVariable("$b"); found {
+		t.Error("Variable should not be defined: $b")
+	}
+}
+
+func TestScriptRunnerExit(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	scriptRunner := commandParser.GetScriptRunner()
+
+	err := scriptRunner.Run(strings.NewReader("exit 2\n$a = 1\n"), "test.gc")
+	if !errors.Is(err, ErrExit) {
+		t.Error("Expected:", ErrExit, "Actual:", err)
+	}
+
+	if _, found := evaluator.Variables.GetVariable("$a"); found {
+		t.Error("Variable should not be defined: $a")
+	}
+}
+
+func TestScriptRunnerInterrupt(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	scriptRunner := commandParser.GetScriptRunner()
+
+	scriptRunner.Interrupt()
+	err := scriptRunner.Run(strings.NewReader("$a = 1\n"), "test.gc")
+	if err != ErrInterrupted {
+		t.Error("Expected:", ErrInterrupted, "Actual:", err)
+	}
+
+	scriptRunner.ClearInterrupt()
+	err = scriptRunner.Run(strings.NewReader("$a = 1\n"), "test.gc")
+	if err != nil {
+		t.Error("Expected:", nil, "Actual:", err)
+	}
+}
+
+func TestScriptErrorPosition(t *testing.T) {
+	scriptError := &ScriptError{"test.gc", 3, expreval.ErrSyntax}
+	if scriptError.Error() != "test.gc:3: syntax error" {
+		t.Error("Expected:", "test.gc:3: syntax error", "Actual:", scriptError.Error())
+	}
+}
+
+func TestPrintErrorOutsideInput(t *testing.T) {
+	// A position beyond the input is printed without the input, rather than a caret in the wrong place.
+	output := new(bytes.Buffer)
+	PrintError(output, &expreval.EvaluationError{Err: expreval.ErrSyntax, Start: 9, End: 10, Column: 10}, "1 + 2")
+	assertOutput(t, output, "ERROR: syntax error at column 10\n")
+}
+
+func TestCommandSource(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "source.gc")
+	os.WriteFile(fileName, []byte("$a = 42\n"), 0644)
+
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	command, arguments, _ := commandParser.ParseCommand("source " + fileName + "\n")
+	assertCommand(t, command, "source")
+	assertArguments(t, arguments, []Argument{{expreval.TokenEnd, fileName, 0.0, 0, 0}})
+
+	// The results are written to the output given to the command.
+	scriptRunner := commandParser.GetScriptRunner()
+	scriptRunner.Output = io.Discard
+	output := new(bytes.Buffer)
+	err := command.Execute(arguments, output)
+	if err != nil {
+		t.Error("Expected:", nil, "Actual:", err)
+	}
+
+	assertOutput(t, output, "42\n")
+	assertScriptVariable(t, evaluator, "$a", 42)
+	if scriptRunner.Output != io.Discard {
+		t.Error("Expected:", io.Discard, "Actual:", scriptRunner.Output)
+	}
+}
+
+func TestCommandSourceRecursive(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "recursive.gc")
+	os.WriteFile(fileName, []byte("onerror stop\nsource "+fileName+"\n"), 0644)
+
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+
+	err := commandParser.GetScriptRunner().RunFile(fileName)
+	if err != ErrScriptFailed {
+		t.Error("Expected:", ErrScriptFailed, "Actual:", err)
+	}
+}
+
+func TestCommandSourceNoFile(t *testing.T) {
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	command, _, err := commandParser.ParseCommand("source")
+	assertNilCommandAndError(t, command, err, ErrInvalidArgs)
+}
+
+func assertScriptVariable(t *testing.T, evaluator *expreval.Evaluator, variableName string, expectedValue float64) {
+	value, found := evaluator.Variables.GetVariable(variableName)
+	if !found || value != expectedValue {
+		t.Error("Variable:", variableName, "Expected:", expectedValue, "Actual:", value)
+	}
+}
@@ -0,0 +1,338 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"alanmitic/gocalc/resultformatter"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Version of the state file format.  Files with an earlier version can still be loaded.
+	StateVersion = 1
+)
+
+var ErrStateVersion = errors.New("unsupported state file version")
+var ErrStateSyntax = errors.New("invalid state file")
+
+// Output modes, named after the commands that select them.
+var OutputModes = map[string]resultformatter.OutputMode{
+	"fix":  resultformatter.OutputModeFixed,
+	"real": resultformatter.OutputModeReal,
+	"sci":  resultformatter.OutputModeScientific,
+	"bin":  resultformatter.OutputModeBinary,
+	"oct":  resultformatter.OutputModeOctal,
+	"hex":  resultformatter.OutputModeHexadecimal,
+}
+
+// Non-finite policies, named as for the nonfinite command.
+var nonFinitePolicies = map[string]expreval.NonFinitePolicy{
+	"error":     expreval.NonFiniteError,
+	"propagate": expreval.NonFinitePropagate,
+}
+
+// State of a session that is saved to a file: the variables, the output format, the settings, the macros and the
+// aliases.
+type State struct {
+	Version         int                   `json:"version"`
+	OutputMode      string                `json:"outputMode"`
+	Precision       int                   `json:"precision"`
+	Strict          bool                  `json:"strict"`
+	NonFinite       string                `json:"nonFinite"`
+	ContinueFromAns bool                  `json:"continueFromAns"`
+	Variables       map[string]StateValue `json:"variables"`
+	// Names of the variables that are constants.
+	Constants []string `json:"constants,omitempty"`
+	// Lines of the macros, by name.
+	Macros map[string][]string `json:"macros,omitempty"`
+	// Text that replaces each alias, by name.
+	Aliases map[string]string `json:"aliases,omitempty"`
+}
+
+// Value saved as a JSON number, or as "inf", "-inf" or "nan" which cannot be written as JSON numbers.
+type StateValue float64
+
+func (value StateValue) MarshalJSON() ([]byte, error) {
+	number := float64(value)
+	switch {
+	case math.IsNaN(number):
+		return []byte(`"nan"`), nil
+	case math.IsInf(number, 1):
+		return []byte(`"inf"`), nil
+	case math.IsInf(number, -1):
+		return []byte(`"-inf"`), nil
+	default:
+		return []byte(strconv.FormatFloat(number, 'g', -1, 64)), nil
+	}
+}
+
+func (value *StateValue) UnmarshalJSON(data []byte) error {
+	var text string
+	if json.Unmarshal(data, &text) == nil {
+		switch text {
+		case "nan":
+			*value = StateValue(math.NaN())
+		case "inf":
+			*value = StateValue(math.Inf(1))
+		case "-inf":
+			*value = StateValue(math.Inf(-1))
+		default:
+			return fmt.Errorf("%w: value %s", ErrStateSyntax, text)
+		}
+		return nil
+	}
+
+	var number float64
+	if err := json.Unmarshal(data, &number); err != nil {
+		return fmt.Errorf("%w: value %s", ErrStateSyntax, string(data))
+	}
+	*value = StateValue(number)
+	return nil
+}
+
+// Gets the path of the default state file, in the user's configuration directory.
+func DefaultStateFile() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "gocalc", "state.json"), nil
+}
+
+// Gets the current state of the command parser, its evaluator and its result formatter.
+func GetState(commandParser *CommandParser) *State {
+	evaluator := commandParser.evaluator
+	resultFormatter := commandParser.resultFormatter
+
+	state := State{}
+	state.Version = StateVersion
+	state.Precision = resultFormatter.GetPrecision()
+	state.Strict = evaluator.Strict
+	state.ContinueFromAns = evaluator.ContinueFromAns
+
+	for name, outputMode := range OutputModes {
+		if outputMode == resultFormatter.GetOutputMode() {
+			state.OutputMode = name
+		}
+	}
+
+	for name, policy := range nonFinitePolicies {
+		if policy == evaluator.NonFinitePolicy {
+			state.NonFinite = name
+		}
+	}
+
+	state.Variables = make(map[string]StateValue)
+	for _, name := range evaluator.GetVariableNames() {
+		value, _ := evaluator.Variables.GetVariable(name)
+		state.Variables[name] = StateValue(value)
+		if evaluator.IsConstant(name) {
+			state.Constants = append(state.Constants, name)
+		}
+	}
+	sort.Strings(state.Constants)
+
+	for name, command := range commandParser.commands {
+		if macro, isMacro := command.(*Macro); isMacro {
+			if state.Macros == nil {
+				state.Macros = make(map[string][]string)
+			}
+			state.Macros[name] = append([]string{}, macro.lines...)
+		}
+	}
+
+	if len(commandParser.aliases) > 0 {
+		state.Aliases = commandParser.GetAliases()
+	}
+
+	return &state
+}
+
+// Applies the state to the command parser, its evaluator and its result formatter.  The saved variables, macros and
+// aliases are set, and others are kept.  The state is checked before anything is changed, and nothing is changed if it
+// cannot all be applied.
+func (state *State) Apply(commandParser *CommandParser) error {
+	evaluator := commandParser.evaluator
+	resultFormatter := commandParser.resultFormatter
+
+	if state.Version < 1 || state.Version > StateVersion {
+		return fmt.Errorf("%w: %d", ErrStateVersion, state.Version)
+	}
+
+	outputMode, found := OutputModes[state.OutputMode]
+	if !found {
+		return fmt.Errorf("%w: output mode %s", ErrStateSyntax, state.OutputMode)
+	}
+
+	if state.Precision < -1 || state.Precision > resultformatter.MaxPrecision {
+		return fmt.Errorf("%w: precision %d", ErrStateSyntax, state.Precision)
+	}
+
+	policy, found := nonFinitePolicies[state.NonFinite]
+	if !found {
+		return fmt.Errorf("%w: nonfinite %s", ErrStateSyntax, state.NonFinite)
+	}
+
+	for name, value := range state.Variables {
+		lexAn := expreval.CreateLexicalAnalyser(name)
+		if lexAn.ParseNextToken() != expreval.TokenVariable || lexAn.GetTextValue() != name {
+			return fmt.Errorf("%w: variable %s", ErrStateSyntax, name)
+		}
+
+		// A constant is only kept if it already has the saved value, e.g. when the same state is loaded again.
+		if evaluator.IsConstant(name) && !hasValue(evaluator, name, float64(value)) {
+			return fmt.Errorf("%w: %s", expreval.ErrConstantAssignment, name)
+		}
+	}
+
+	for _, name := range state.Constants {
+		if _, found := state.Variables[name]; !found || !expreval.IsAssignableVariable(name) {
+			return fmt.Errorf("%w: constant %s", ErrStateSyntax, name)
+		}
+	}
+
+	if err := state.checkMacrosAndAliases(commandParser); err != nil {
+		return err
+	}
+
+	// Whether a variable is read only is only known when it is set, so the variables are set before anything else is
+	// changed.
+	if err := state.applyVariables(evaluator); err != nil {
+		return err
+	}
+
+	resultFormatter.SetOutputMode(outputMode)
+	resultFormatter.SetPrecision(state.Precision)
+	evaluator.Strict = state.Strict
+	evaluator.NonFinitePolicy = policy
+	evaluator.ContinueFromAns = state.ContinueFromAns
+
+	for _, name := range state.Constants {
+		evaluator.MarkConstant(name, true)
+	}
+
+	for name, lines := range state.Macros {
+		macro := Macro{name, append([]string{}, lines...), commandParser.scriptRunner}
+		addCommand(commandParser.commands, &macro)
+	}
+	for name, text := range state.Aliases {
+		commandParser.aliases[name] = strings.Join(strings.Fields(text), " ")
+	}
+
+	return nil
+}
+
+// Checks that the saved macros and aliases can be added: their names must be free, or already used by a macro or alias
+// of the same kind that they replace, and each alias must start with the name of a command or a saved macro.
+func (state *State) checkMacrosAndAliases(commandParser *CommandParser) error {
+	for name := range state.Macros {
+		if err := commandParser.checkName(name); err != nil {
+			return fmt.Errorf("%w: macro %s", err, name)
+		}
+
+		_, isAlias := commandParser.aliases[name]
+		if _, isSavedAlias := state.Aliases[name]; isAlias || isSavedAlias {
+			return fmt.Errorf("%w: %s is an alias", ErrNameConflict, name)
+		}
+	}
+
+	for name, text := range state.Aliases {
+		if err := commandParser.checkName(name); err != nil {
+			return fmt.Errorf("%w: alias %s", err, name)
+		}
+
+		if _, found := commandParser.commands[name]; found {
+			return fmt.Errorf("%w: %s is a macro", ErrNameConflict, name)
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			return fmt.Errorf("%w: alias %s", ErrStateSyntax, name)
+		}
+
+		_, isCommand := commandParser.commands[fields[0]]
+		if _, isSavedMacro := state.Macros[fields[0]]; !isCommand && !isSavedMacro {
+			return fmt.Errorf("%w: %s", ErrNotFound, fields[0])
+		}
+	}
+
+	return nil
+}
+
+// Sets the saved variables that do not already have their saved values, such as the constants.  If a variable cannot
+// be set, e.g. because it is read only, the variables that were set are restored and the error is returned.
+func (state *State) applyVariables(evaluator *expreval.Evaluator) error {
+	names := []string{}
+	for name, value := range state.Variables {
+		if !hasValue(evaluator, name, float64(value)) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	previousValues := make(map[string]float64)
+	for index, name := range names {
+		if value, found := evaluator.Variables.GetVariable(name); found {
+			previousValues[name] = value
+		}
+
+		if err := evaluator.Variables.SetVariable(name, float64(state.Variables[name])); err != nil {
+			for _, setName := range names[:index] {
+				if value, found := previousValues[setName]; found {
+					evaluator.Variables.SetVariable(setName, value)
+				} else {
+					evaluator.Variables.DeleteVariable(setName)
+				}
+			}
+			return fmt.Errorf("%w: %s", err, name)
+		}
+	}
+
+	return nil
+}
+
+// Reports whether a variable has the value, treating NaN as equal to itself.
+func hasValue(evaluator *expreval.Evaluator, name string, value float64) bool {
+	currentValue, found := evaluator.Variables.GetVariable(name)
+	return found && (currentValue == value || math.IsNaN(currentValue) && math.IsNaN(value))
+}
+
+// Saves the state of the command parser to the file, creating its directory if needed.
+func SaveStateFile(fileName string, commandParser *CommandParser) error {
+	data, err := json.MarshalIndent(GetState(commandParser), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(fileName), 0700)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(fileName, append(data, '\n'), 0600)
+}
+
+// Loads the state saved in the file into the command parser.
+func LoadStateFile(fileName string, commandParser *CommandParser) error {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+
+	state := State{}
+	if err := json.Unmarshal(data, &state); err != nil {
+		if errors.Is(err, ErrStateSyntax) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", ErrStateSyntax, err)
+	}
+
+	return state.Apply(commandParser)
+}
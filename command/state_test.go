@@ -0,0 +1,183 @@
+package command
+
+import (
+	"alanmitic/gocalc/expreval"
+	"alanmitic/gocalc/resultformatter"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveAndLoadState(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "state", "test.json")
+
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	for _, line := range []string{"hex", "strict off", "nonfinite propagate", "continue off", "$x = 10",
+		"$big = 10 ^ 400", "$nan = nan", "const $g = 9.80665", "macro area", "$1 * $2", "end", "alias a = area",
+		"alias h = hex", "save " + fileName} {
+		err := commandParser.GetScriptRunner().ExecuteLine(line)
+		if err != nil {
+			t.Fatal("Line:", line, "Expected:", nil, "Actual:", err)
+		}
+	}
+
+	loadedEvaluator := expreval.NewEvaluator()
+	loadedEvaluator.Variables.SetVariable("$kept", 1)
+	loadedResultFormatter := resultformatter.NewResultFormatter()
+	commandParser = NewCommandParser(loadedEvaluator, loadedResultFormatter)
+	command, arguments, _ := commandParser.ParseCommand("load " + fileName)
+	assertCommand(t, command, "load")
+	err := command.Execute(arguments, io.Discard)
+	if err != nil {
+		t.Fatal("Expected:", nil, "Actual:", err)
+	}
+
+	assertOutputModeAndPrecision(t, loadedResultFormatter, resultformatter.OutputModeHexadecimal, -1)
+	if loadedEvaluator.Strict || loadedEvaluator.NonFinitePolicy != expreval.NonFinitePropagate ||
+		loadedEvaluator.ContinueFromAns {
+		t.Error("Expected:", "settings loaded", "Actual:", loadedEvaluator)
+	}
+
+	assertScriptVariable(t, loadedEvaluator, "$x", 10)
+	assertScriptVariable(t, loadedEvaluator, "$big", math.Inf(1))
+	assertScriptVariable(t, loadedEvaluator, "$kept", 1)
+	assertScriptVariable(t, loadedEvaluator, "$g", 9.80665)
+	if !loadedEvaluator.IsConstant("$g") || loadedEvaluator.IsConstant("$x") {
+		t.Error("Expected:", "$g constant", "Actual:", loadedEvaluator.Constants)
+	}
+	if value, _ := loadedEvaluator.Variables.GetVariable("$nan"); !math.IsNaN(value) {
+		t.Error("Expected:", math.NaN(), "Actual:", value)
+	}
+
+	aliases := commandParser.GetAliases()
+	if len(aliases) != 2 || aliases["a"] != "area" || aliases["h"] != "hex" {
+		t.Error("Expected:", "a and h", "Actual:", aliases)
+	}
+	err = commandParser.GetScriptRunner().ExecuteLine("a 3, 4")
+	if err != nil {
+		t.Fatal("Expected:", nil, "Actual:", err)
+	}
+	assertScriptVariable(t, loadedEvaluator, "$ans", 12)
+}
+
+func TestStateFileIsReadable(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "test.json")
+
+	evaluator := expreval.NewEvaluator()
+	evaluator.Variables.SetVariable("$x", 1.5)
+	evaluator.Variables.SetVariable("$y", math.Inf(-1))
+	SaveStateFile(fileName, NewCommandParser(evaluator, resultformatter.NewResultFormatter()))
+
+	data, _ := os.ReadFile(fileName)
+	for _, expected := range []string{`"version": 1`, `"outputMode": "real"`, `"$x": 1.5`, `"$y": "-inf"`} {
+		if !strings.Contains(string(data), expected) {
+			t.Error("Expected:", expected, "Actual:", string(data))
+		}
+	}
+}
+
+func TestLoadStateErrors(t *testing.T) {
+	directory := t.TempDir()
+	assertLoadStateError(t, directory, `{"version": 2, "outputMode": "fix", "nonFinite": "error"}`, ErrStateVersion)
+	assertLoadStateError(t, directory, `{"version": 1, "outputMode": "dec", "nonFinite": "error"}`, ErrStateSyntax)
+	assertLoadStateError(t, directory, `{"version": 1, "outputMode": "fix", "nonFinite": "error",
+		"variables": {"x": 1}}`, ErrStateSyntax)
+	assertLoadStateError(t, directory, `{"version": 1, "outputMode": "fix", "nonFinite": "error",
+		"variables": {"$x": "lots"}}`, ErrStateSyntax)
+	assertLoadStateError(t, directory, `{"version": 1, "outputMode": "fix", "precision": 1000, "nonFinite": "error"}`,
+		ErrStateSyntax)
+	assertLoadStateError(t, directory, `{"version": 1, "outputMode": "fix", "precision": -2, "nonFinite": "error"}`,
+		ErrStateSyntax)
+	assertLoadStateError(t, directory, `not json`, ErrStateSyntax)
+	assertLoadStateError(t, directory, `{"version": 1, "outputMode": "fix", "nonFinite": "error",
+		"macros": {"fix": ["1"]}}`, ErrNameConflict)
+	assertLoadStateError(t, directory, `{"version": 1, "outputMode": "fix", "nonFinite": "error",
+		"macros": {"m": ["1"]}, "aliases": {"m": "fix"}}`, ErrNameConflict)
+	assertLoadStateError(t, directory, `{"version": 1, "outputMode": "fix", "nonFinite": "error",
+		"aliases": {"f": "missing 2"}}`, ErrNotFound)
+	assertLoadStateError(t, directory, `{"version": 1, "outputMode": "fix", "nonFinite": "error",
+		"variables": {"$x": 1}, "aliases": {"2": "fix"}}`, ErrInvalidArgs)
+
+	err := LoadStateFile(filepath.Join(directory, "missing.json"),
+		NewCommandParser(expreval.NewEvaluator(), resultformatter.NewResultFormatter()))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Error("Expected:", os.ErrNotExist, "Actual:", err)
+	}
+}
+
+// Variables that are read only unless their names start with $w, as a program embedding the calculator might supply.
+type partlyReadOnlyVariables struct {
+	expreval.VariableMap
+}
+
+func (variables partlyReadOnlyVariables) SetVariable(name string, value float64) error {
+	if !strings.HasPrefix(name, "$w") {
+		return expreval.ErrReadOnlyVariable
+	}
+	return variables.VariableMap.SetVariable(name, value)
+}
+
+func TestLoadStateVariableErrors(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "state.json")
+	evaluator := expreval.NewEvaluator()
+	evaluator.Variables = partlyReadOnlyVariables{expreval.VariableMap{"$wa": 5, "$x": 1}}
+	evaluator.SetConstant("$wg", 9.80665)
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+
+	// Nothing is changed when a variable is read only.
+	os.WriteFile(fileName, []byte(`{"version": 1, "outputMode": "fix", "nonFinite": "error",
+		"variables": {"$wa": 1, "$wb": 2, "$x": 3}}`), 0600)
+	err := LoadStateFile(fileName, commandParser)
+	if !errors.Is(err, expreval.ErrReadOnlyVariable) {
+		t.Error("Expected:", expreval.ErrReadOnlyVariable, "Actual:", err)
+	}
+
+	assertScriptVariable(t, evaluator, "$wa", 5)
+	assertScriptVariable(t, evaluator, "$x", 1)
+	_, found := evaluator.Variables.GetVariable("$wb")
+	if found || resultFormatter.GetOutputMode() != resultformatter.OutputModeReal {
+		t.Error("Expected:", "no changes", "Actual:", evaluator.GetVariableNames(), resultFormatter.GetOutputMode())
+	}
+
+	// A constant cannot be changed, but can be loaded again with the same value.
+	os.WriteFile(fileName, []byte(`{"version": 1, "outputMode": "fix", "nonFinite": "error",
+		"variables": {"$wg": 10}, "constants": ["$wg"]}`), 0600)
+	err = LoadStateFile(fileName, commandParser)
+	if !errors.Is(err, expreval.ErrConstantAssignment) {
+		t.Error("Expected:", expreval.ErrConstantAssignment, "Actual:", err)
+	}
+
+	SaveStateFile(fileName, commandParser)
+	err = LoadStateFile(fileName, commandParser)
+	if err != nil {
+		t.Error("Expected:", nil, "Actual:", err)
+	}
+	assertScriptVariable(t, evaluator, "$wg", 9.80665)
+}
+
+func assertLoadStateError(t *testing.T, directory string, contents string, expectedError error) {
+	fileName := filepath.Join(directory, "state.json")
+	os.WriteFile(fileName, []byte(contents), 0600)
+
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	commandParser := NewCommandParser(evaluator, resultFormatter)
+	err := LoadStateFile(fileName, commandParser)
+	if !errors.Is(err, expectedError) {
+		t.Error("Contents:", contents, "Expected:", expectedError, "Actual:", err)
+	}
+
+	// Nothing is changed by an invalid state file.
+	_, isMacro := commandParser.commands["m"]
+	if resultFormatter.GetOutputMode() != resultformatter.OutputModeReal || len(evaluator.GetVariableNames()) != 0 ||
+		isMacro || len(commandParser.GetAliases()) != 0 {
+		t.Error("Contents:", contents, "Expected:", "no changes")
+	}
+}
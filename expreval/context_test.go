@@ -0,0 +1,145 @@
+package expreval
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestNewContext(t *testing.T) {
+	evaluator := newFunctionEvaluator(t)
+	evaluator.Strict = false
+	evaluator.SetConstant("$g", 9.8)
+	evaluator.Evaluate("$a = 2")
+
+	context := evaluator.NewContext()
+	if context.Strict || len(context.History) != 0 || context.MaxHistory != evaluator.MaxHistory {
+		t.Error("Expected:", "settings without history", "Actual:", context.Strict, context.History)
+	}
+
+	result, err := context.Evaluate("$a = $a + hypot(3, 4)")
+	assertEvaluatedResult(t, 7, nil, result, err)
+	assertVariableValue(t, evaluator, "$a", 2)
+
+	_, err = context.Evaluate("$g = 1")
+	if !errors.Is(err, ErrConstantAssignment) {
+		t.Error("Expected:", ErrConstantAssignment, "Actual:", err)
+	}
+
+	// The context reads the evaluator's later variables, but not its later functions, and its constants are its own.
+	evaluator.Evaluate("$b = 1")
+	evaluator.RegisterFunction("twice", 1, func(arguments []float64) (float64, error) {
+		return 2 * arguments[0], nil
+	})
+
+	result, err = context.Evaluate("$b")
+	assertEvaluatedResult(t, 1, nil, result, err)
+
+	_, err = context.Evaluate("twice($b)")
+	if !errors.Is(err, ErrUndefinedFunction) || context.IsFunction("twice") {
+		t.Error("Expected:", ErrUndefinedFunction, "Actual:", err)
+	}
+
+	context.SetConstant("$k", 1)
+	if evaluator.IsConstant("$k") || !context.IsConstant("$g") {
+		t.Error("Expected:", "separate constants", "Actual:", evaluator.Constants, context.Constants)
+	}
+
+	if len(evaluator.History) != 2 || len(context.History) != 2 {
+		t.Error("Expected:", "separate history", "Actual:", evaluator.History, context.History)
+	}
+}
+
+// Variables supplied by a program, which it changes while contexts read them, so they are guarded by a lock.
+type lockedVariables struct {
+	mutex     sync.RWMutex
+	variables VariableMap
+}
+
+func (locked *lockedVariables) GetVariable(name string) (float64, bool) {
+	locked.mutex.RLock()
+	defer locked.mutex.RUnlock()
+	return locked.variables.GetVariable(name)
+}
+
+func (locked *lockedVariables) SetVariable(name string, value float64) error {
+	locked.mutex.Lock()
+	defer locked.mutex.Unlock()
+	return locked.variables.SetVariable(name, value)
+}
+
+func (locked *lockedVariables) DeleteVariable(name string) error {
+	locked.mutex.Lock()
+	defer locked.mutex.Unlock()
+	return locked.variables.DeleteVariable(name)
+}
+
+func (locked *lockedVariables) GetVariableNames() []string {
+	locked.mutex.RLock()
+	defer locked.mutex.RUnlock()
+	return locked.variables.GetVariableNames()
+}
+
+// Evaluates in contexts from several goroutines while functions are registered with the evaluator they were created
+// from and the program changes its variables.  Run with -race to check that the contexts share no state that is
+// changed.
+func TestContextsConcurrently(t *testing.T) {
+	programVariables := &lockedVariables{variables: VariableMap{"$rate": 1}}
+	evaluator := newFunctionEvaluator(t)
+	evaluator.Variables = NewLayeredVariables(NewVariableMap(), programVariables)
+	evaluator.SetConstant("$g", 10)
+
+	const goroutines = 8
+	const iterations = 200
+
+	contexts := []*Evaluator{}
+	for index := 0; index < goroutines; index++ {
+		contexts = append(contexts, evaluator.NewContext())
+	}
+
+	var waitGroup sync.WaitGroup
+	errs := make(chan error, goroutines)
+	for index, context := range contexts {
+		waitGroup.Add(1)
+		go func(index int, context *Evaluator) {
+			defer waitGroup.Done()
+
+			stack := NewRPNStack(context)
+			for iteration := 0; iteration < iterations; iteration++ {
+				expression := fmt.Sprintf("$x = %d * $g * $rate + hypot(3, 4)", index)
+				result, err := context.Evaluate(expression)
+				if err != nil || result != float64(index*10+5) {
+					errs <- fmt.Errorf("%s = %v, %v", expression, result, err)
+					return
+				}
+
+				if err := stack.Evaluate("$x 3 4 hypot + drop"); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(index, context)
+	}
+
+	for iteration := 0; iteration < iterations; iteration++ {
+		programVariables.SetVariable("$rate", 1)
+		evaluator.RegisterFunction(fmt.Sprintf("f%d", iteration), 0, func(arguments []float64) (float64, error) {
+			return math.Pi, nil
+		})
+	}
+
+	waitGroup.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error("Expected:", nil, "Actual:", err)
+	}
+
+	if _, found := evaluator.Variables.GetVariable("$x"); found {
+		t.Error("Variable should not be defined: $x")
+	}
+	for index, context := range contexts {
+		assertVariableValue(t, context, "$x", float64(index*10+5))
+	}
+}
@@ -1,6 +1,8 @@
 package expreval
 
 import (
+	"errors"
+	"math"
 	"testing"
 )
 
@@ -98,8 +100,76 @@ func TestEvaluateUseVariable(t *testing.T) {
 	assertVariableValue(t, evaluator, "$ans", 9)
 }
 
+func TestEvaluateUndefinedVariable(t *testing.T) {
+	evaluator := NewEvaluator()
+	evaluator.Evaluate("$rate = 5")
+	evaluator.Evaluate("$rates = 6")
+	result, err := evaluator.Evaluate("2 * $ratte")
+	assertEvaluatedResult(t, 0.0, ErrUndefinedVariable, result, err)
+
+	expectedMessage := "undefined variable $ratte at column 5, did you mean $rate or $rates?"
+	if err.Error() != expectedMessage {
+		t.Error("Expected:", expectedMessage, "Actual:", err.Error())
+	}
+}
+
+func TestEvaluateUndefinedVariableNotStrict(t *testing.T) {
+	evaluator := NewEvaluator()
+	evaluator.Strict = false
+	result, err := evaluator.Evaluate("2 + $undefined")
+	assertEvaluatedResult(t, 2, nil, result, err)
+}
+
+func TestEvaluateOverflow(t *testing.T) {
+	evaluator := NewEvaluator()
+	result, err := evaluator.Evaluate("10 ^ 400")
+	assertEvaluatedResult(t, 0.0, ErrArithmeticOverflow, result, err)
+	if err.Error() != "overflow in power at column 4" {
+		t.Error("Expected:", "overflow in power at column 4", "Actual:", err.Error())
+	}
+
+	result, err = evaluator.Evaluate("1 + 10 ^ 300 * 10 ^ 300")
+	assertEvaluatedResult(t, 0.0, ErrArithmeticOverflow, result, err)
+}
+
+func TestEvaluateUndefinedResult(t *testing.T) {
+	evaluator := NewEvaluator()
+	result, err := evaluator.Evaluate("(-8) ^ (1 / 3)")
+	assertEvaluatedResult(t, 0.0, ErrUndefinedResult, result, err)
+}
+
+func TestEvaluatePropagateNonFinite(t *testing.T) {
+	evaluator := NewEvaluator()
+	evaluator.NonFinitePolicy = NonFinitePropagate
+	result, err := evaluator.Evaluate("10 ^ 400")
+	assertEvaluatedResult(t, math.Inf(1), nil, result, err)
+	assertVariableValue(t, evaluator, "$ans", math.Inf(1))
+
+	result, _ = evaluator.Evaluate("(-8) ^ (1 / 3)")
+	if !math.IsNaN(result) {
+		t.Error("Expected:", math.NaN(), "Actual:", result)
+	}
+}
+
+func TestEvaluateSpecialValueLiterals(t *testing.T) {
+	evaluator := NewEvaluator()
+	result, err := evaluator.Evaluate("-inf + 1")
+	assertEvaluatedResult(t, math.Inf(-1), nil, result, err)
+
+	result, _ = evaluator.Evaluate("nan * 2")
+	if !math.IsNaN(result) {
+		t.Error("Expected:", math.NaN(), "Actual:", result)
+	}
+
+	result, err = evaluator.Evaluate("2 * pi")
+	assertEvaluatedResult(t, 2*math.Pi, nil, result, err)
+
+	result, err = evaluator.Evaluate("infinity")
+	assertEvaluatedResult(t, 0.0, ErrPrimaryExpected, result, err)
+}
+
 func assertVariableValue(t *testing.T, evaluator *Evaluator, variableName string, expectedvariableValue float64) {
-	actualVariableValue, variableFound := evaluator.VariableStore[variableName]
+	actualVariableValue, variableFound := evaluator.Variables.GetVariable(variableName)
 
 	if !variableFound {
 		t.Error("Variable not found:", variableName)
@@ -115,7 +185,105 @@ func assertEvaluatedResult(t *testing.T, expectedResult float64, expectedError e
 		t.Error("Expected:", expectedResult, "Actual:", actualResult)
 	}
 
-	if actualError != expectedError {
+	if !errors.Is(actualError, expectedError) {
 		t.Error("Expected:", expectedError, "Actual:", actualError)
 	}
 }
+
+func TestEvaluateErrorPosition(t *testing.T) {
+	assertEvaluationError(t, "2 * (3 + (1 / 2)", ErrMissingClosingParentheses, 17, TokenEnd)
+	assertEvaluationError(t, "2 * (3 + 1))", ErrUnexpectedRightParentheses, 12, TokenRParen)
+	assertEvaluationError(t, "1 + * 2", ErrPrimaryExpected, 5, TokenOpMultiply)
+	assertEvaluationError(t, "1 + 2 $a", ErrSyntax, 7, TokenVariable)
+	assertEvaluationError(t, "£ + 1", ErrSyntax, 1, TokenBad)
+	assertEvaluationError(t, "£ + 1 / (1 - 1)", ErrSyntax, 1, TokenBad)
+	assertEvaluationError(t, "£1 / (1 - 1)", ErrSyntax, 1, TokenBad)
+	assertEvaluationError(t, "1 + 1 / (1 - 1)", ErrDivideByZero, 7, TokenOpDivide)
+}
+
+func assertEvaluationError(t *testing.T, expression string, expectedError error, expectedColumn int, expectedToken LexAnToken) {
+	evaluator := NewEvaluator()
+	_, err := evaluator.Evaluate(expression)
+
+	var evaluationError *EvaluationError
+	if !errors.As(err, &evaluationError) {
+		t.Fatal("Expected:", "*EvaluationError", "Actual:", err)
+	}
+
+	if !errors.Is(err, expectedError) {
+		t.Error("Expected:", expectedError, "Actual:", evaluationError.Err)
+	}
+
+	if evaluationError.Column != expectedColumn {
+		t.Error("Expected:", expectedColumn, "Actual:", evaluationError.Column)
+	}
+
+	if evaluationError.Actual != expectedToken {
+		t.Error("Expected:", expectedToken, "Actual:", evaluationError.Actual)
+	}
+}
+
+func TestEvaluatePercent(t *testing.T) {
+	evaluator := NewEvaluator()
+
+	result, err := evaluator.Evaluate("10%")
+	assertEvaluatedResult(t, 0.1, nil, result, err)
+	result, err = evaluator.Evaluate("200 * 15%")
+	assertEvaluatedResult(t, 30, nil, result, err)
+
+	// Adding and subtracting a percentage is relative to the left term.
+	result, err = evaluator.Evaluate("200 + 10%")
+	assertEvaluatedResult(t, 220, nil, result, err)
+	result, err = evaluator.Evaluate("200 - 25% + 5")
+	assertEvaluatedResult(t, 155, nil, result, err)
+	result, err = evaluator.Evaluate("200 - -10%")
+	assertEvaluatedResult(t, 220, nil, result, err)
+
+	// Only a right term that is a percentage on its own is relative to the left term.
+	result, err = evaluator.Evaluate("200 + (10%)")
+	assertEvaluatedResult(t, 200.1, nil, result, err)
+	result, err = evaluator.Evaluate("200 + 2 * 10%")
+	assertEvaluatedResult(t, 200.2, nil, result, err)
+	result, err = evaluator.Evaluate("200 + 10% / 2")
+	assertEvaluatedResult(t, 200.05, nil, result, err)
+	result, err = evaluator.Evaluate("200 - 10% ^ 2")
+	assertEvaluatedResult(t, 199.99, nil, result, err)
+
+	result, err = evaluator.Evaluate("10 %%")
+	assertEvaluatedResult(t, 0, ErrSyntax, result, err)
+}
+
+func TestEvaluateContinueFromAns(t *testing.T) {
+	evaluator := NewEvaluator()
+
+	// Without a previous result a leading plus is unary.
+	result, err := evaluator.Evaluate("+5")
+	assertEvaluatedResult(t, 5, nil, result, err)
+
+	result, err = evaluator.Evaluate("* 2")
+	assertEvaluatedResult(t, 10, nil, result, err)
+	result, err = evaluator.Evaluate("+ 10%")
+	assertEvaluatedResult(t, 11, nil, result, err)
+	result, err = evaluator.Evaluate("/ 11 ^ 2")
+	assertEvaluatedResult(t, 1.0/11.0, nil, result, err)
+
+	// A leading minus remains unary.
+	result, err = evaluator.Evaluate("-5")
+	assertEvaluatedResult(t, -5, nil, result, err)
+
+	evaluator.ContinueFromAns = false
+	result, err = evaluator.Evaluate("* 2")
+	assertEvaluatedResult(t, 0, ErrPrimaryExpected, result, err)
+}
+
+func TestEvaluateContinueFromAnsErrorPosition(t *testing.T) {
+	evaluator := NewEvaluator()
+	evaluator.Evaluate("1")
+
+	_, err := evaluator.Evaluate("* (2 + )")
+
+	var evaluationError *EvaluationError
+	if !errors.As(err, &evaluationError) || evaluationError.Column != 8 {
+		t.Error("Expected:", "primary expected at column 8", "Actual:", err)
+	}
+}
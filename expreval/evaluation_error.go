@@ -0,0 +1,63 @@
+package expreval
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Error returned by the evaluator that records where in the expression the problem was found.  The underlying sentinel
+// error, such as ErrSyntax, is available via errors.Is.
+type EvaluationError struct {
+	// The sentinel error describing the problem.
+	Err error
+	// Byte offsets of the start and end of the offending token within the expression.
+	Start int
+	End   int
+	// Column of the offending token, starting at 1 and counted in characters.
+	Column int
+	// Tokens that would have been accepted at this position, if known.
+	Expected []LexAnToken
+	// Token found at this position.
+	Actual LexAnToken
+	// Name of the variable the error relates to, if any.
+	Name string
+	// Close matches for the name that the user may have meant.
+	Suggestions []string
+	// Name of the operation that failed, if any.
+	Operation string
+}
+
+func (evaluationError *EvaluationError) Error() string {
+	message := evaluationError.Err.Error()
+	if evaluationError.Name != "" {
+		message += " " + evaluationError.Name
+	}
+
+	if evaluationError.Operation != "" {
+		message += " in " + evaluationError.Operation
+	}
+
+	message += fmt.Sprintf(" at column %d", evaluationError.Column)
+
+	if len(evaluationError.Suggestions) > 0 {
+		message += ", did you mean " + strings.Join(evaluationError.Suggestions, " or ") + "?"
+	}
+
+	return message
+}
+
+func (evaluationError *EvaluationError) Unwrap() error {
+	return evaluationError.Err
+}
+
+// Creates an error positioned at the current token of the lexical analyser.
+func newEvaluationError(err error, lexAn LexicalAnalyser, expected ...LexAnToken) *EvaluationError {
+	start, end := lexAn.GetTokenSpan()
+	return &EvaluationError{Err: err, Start: start, End: end, Expected: expected, Actual: lexAn.GetCurrentToken()}
+}
+
+// Sets the column from the byte offset of the error within the expression.
+func (evaluationError *EvaluationError) setColumn(expression string) {
+	evaluationError.Column = utf8.RuneCountInString(expression[:evaluationError.Start]) + 1
+}
@@ -1,8 +1,10 @@
 package expreval
 
 import (
+	"context"
 	"errors"
 	"math"
+	"sort"
 )
 
 var ErrPrimaryExpected = errors.New("primary expected")
@@ -10,26 +12,168 @@ var ErrSyntax = errors.New("syntax error")
 var ErrDivideByZero = errors.New("divide by zero")
 var ErrMissingClosingParentheses = errors.New("')' expected")
 var ErrUnexpectedRightParentheses = errors.New("unexpected ')'")
+var ErrUndefinedVariable = errors.New("undefined variable")
+var ErrArithmeticOverflow = errors.New("overflow")
+var ErrUndefinedResult = errors.New("undefined result")
+
+// How the evaluator treats an operation on finite values that results in an infinite value or NaN.
+type NonFinitePolicy int
+
+const (
+	// Return an error naming the operation.
+	NonFiniteError NonFinitePolicy = iota
+	// Return the infinite or NaN value as the result.
+	NonFinitePropagate
+)
+
+// Literals for the special floating point values and for pi.
+var specialValueLiterals = map[string]float64{
+	"inf": math.Inf(1),
+	"nan": math.NaN(),
+	"pi":  math.Pi,
+}
 
+// Reports whether the identifier is a literal value, such as inf, rather than a command name.
+func IsLiteral(identifier string) bool {
+	_, found := specialValueLiterals[identifier]
+	return found
+}
+
+// Variable holding the result of the last evaluation.
+const ansVariable = "$ans"
+
+// Gets the names of the literal values, sorted.
+func GetLiterals() []string {
+	literals := []string{}
+	for literal := range specialValueLiterals {
+		literals = append(literals, literal)
+	}
+	sort.Strings(literals)
+	return literals
+}
+
+// Tokens that may follow a complete term.
+var expectedOperators = []LexAnToken{TokenOpPlus, TokenOpMinus, TokenOpMultiply, TokenOpDivide, TokenOpPower, TokenPercent,
+	TokenEnd}
+
+// Operators that continue from the previous result when they start an expression.  Minus is not included so that "-5"
+// remains negative five.
+var continuingOperators = []LexAnToken{TokenOpPlus, TokenOpMultiply, TokenOpDivide, TokenOpPower}
+
+// Tokens that may start a primary.
+var expectedPrimaries = []LexAnToken{TokenNumber, TokenVariable, TokenLParen, TokenOpPlus, TokenOpMinus}
+
+// Evaluates expressions, keeping the variables and history between them.  An evaluator must only be used by one
+// goroutine at a time, so NewContext creates one for each goroutine that evaluates expressions concurrently.
 type Evaluator struct {
-	VariableStore map[string]float64
+	// Values of the variables, which by default are held in a VariableMap.
+	Variables VariableResolver
+	// When set, using a variable that has not been assigned is an error rather than evaluating to 0.
+	Strict bool
+	// How operations that overflow or have an undefined result are treated.
+	NonFinitePolicy NonFinitePolicy
+	// Results of the evaluated expressions, oldest first.  The results are referenced as "$ans[3]", "$_3" or relative
+	// to the latest as "$ans[-2]".
+	History []HistoryEntry
+	// Number of entries kept in the history, with zero disabling it.
+	MaxHistory int
+	// When set, an expression starting with an operator other than minus, e.g. "* 2", continues from $ans.
+	ContinueFromAns bool
+	// Names of the variables that are constants, which cannot be assigned.  The map is shared with the contexts created
+	// from the evaluator, so it is replaced rather than changed.
+	Constants map[string]bool
+	// Limits on the work done by an evaluation.
+	Limits Limits
+	// Functions that can be called in an expression, by name.  The map is shared with the contexts created from the
+	// evaluator, so it is replaced rather than changed.
+	functions map[string]registeredFunction
+	// The evaluation in progress, if any.
+	state *evaluationState
 }
 
 func NewEvaluator() *Evaluator {
-	evaluator := Evaluator{make(map[string]float64)}
+	evaluator := Evaluator{NewVariableMap(), true, NonFiniteError, []HistoryEntry{}, DefaultMaxHistory,
+		true, make(map[string]bool), DefaultLimits, make(map[string]registeredFunction), nil}
 	return &evaluator
 }
 
+// Creates an evaluator for another goroutine, e.g. one for each request a service evaluates.  The context has the
+// settings, functions and constants of the evaluator, but its own history.  Variables assigned in the context are kept
+// in its own map, over the evaluator's variables, which the context reads but does not change or copy.  The
+// evaluator's variables must therefore not be changed while contexts evaluate, and a VariableResolver supplied by the
+// program must be safe for concurrent reads.  The functions are shared rather than copied, so they must be safe to call
+// from several goroutines.  Functions and constants added to either evaluator later are not seen by the other.
+func (evaluator *Evaluator) NewContext() *Evaluator {
+	evaluatorContext := *evaluator
+	evaluatorContext.History = []HistoryEntry{}
+	evaluatorContext.state = nil
+	evaluatorContext.Variables = NewLayeredVariables(NewVariableMap(), evaluator.Variables)
+	return &evaluatorContext
+}
+
 func (evaluator *Evaluator) Evaluate(expression string) (float64, error) {
-	lexAn := CreateLexicalAnalyser(expression)
+	return evaluator.EvaluateContext(context.Background(), expression)
+}
+
+// Evaluates the expression, stopping with the context's error, e.g. context.DeadlineExceeded, if the context is done
+// before the evaluation ends.  The evaluation is also stopped by the evaluator's limits.  The context is checked
+// between the numbers, variables, parentheses and function calls of the expression, so a registered function that is
+// slow to return cannot be cancelled while it runs.
+func (evaluator *Evaluator) EvaluateContext(ctx context.Context, expression string) (float64, error) {
+	endEvaluation, err := evaluator.startEvaluation(ctx, expression)
+	if err != nil {
+		return 0.0, err
+	}
+	defer endEvaluation()
+
+	// Continuing from $ans evaluates the expression with $ans in front of it.  Error positions are moved back into the
+	// original expression.
+	prefix := ""
+	if evaluator.isContinuation(expression) {
+		prefix = ansVariable + " "
+	}
+
+	lexAn := CreateLexicalAnalyser(prefix + expression)
 	result, err := evaluator.getTerm(lexAn, 0, 0)
 	if err == nil {
-		evaluator.VariableStore["$ans"] = result
+		err = evaluator.Variables.SetVariable(ansVariable, result)
+	}
+
+	if err == nil {
+		evaluator.addHistoryEntry(expression, result)
+	}
+
+	var evaluationError *EvaluationError
+	if errors.As(err, &evaluationError) {
+		evaluationError.Start = maxInt(evaluationError.Start-len(prefix), 0)
+		evaluationError.End = maxInt(evaluationError.End-len(prefix), 0)
+		evaluationError.setColumn(expression)
 	}
 
 	return result, err
 }
 
+// Reports whether the expression continues from $ans, i.e. it starts with a continuing operator and there is a previous
+// result.
+func (evaluator *Evaluator) isContinuation(expression string) bool {
+	if !evaluator.ContinueFromAns {
+		return false
+	}
+
+	if _, found := evaluator.Variables.GetVariable(ansVariable); !found {
+		return false
+	}
+
+	firstToken := CreateLexicalAnalyser(expression).ParseNextToken()
+	for _, operator := range continuingOperators {
+		if firstToken == operator {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (evaluator *Evaluator) getTerm(lexAn LexicalAnalyser, precedence uint, parenthesesLevel uint) (float64, error) {
 	// Process the terms at the supplied precedence.
 	switch precedence {
@@ -46,7 +190,14 @@ func (evaluator *Evaluator) getTerm(lexAn LexicalAnalyser, precedence uint, pare
 			switch lexAn.GetCurrentToken() {
 			case TokenRParen: // Final exit point (result).
 				if parenthesesLevel == 0 { // Check for too many RPs.
-					return 0.0, ErrUnexpectedRightParentheses
+					return 0.0, newEvaluationError(ErrUnexpectedRightParentheses, lexAn, TokenEnd)
+				}
+
+				return leftTerm, nil
+
+			case TokenComma: // End of a function argument.
+				if parenthesesLevel == 0 {
+					return 0.0, newEvaluationError(ErrSyntax, lexAn, expectedOperators...)
 				}
 
 				return leftTerm, nil
@@ -55,7 +206,7 @@ func (evaluator *Evaluator) getTerm(lexAn LexicalAnalyser, precedence uint, pare
 				return leftTerm, nil
 
 			default: // Systax error in the expression,
-				return 0.0, ErrSyntax
+				return 0.0, newEvaluationError(ErrSyntax, lexAn, expectedOperators...)
 			}
 		}
 
@@ -71,93 +222,58 @@ func (evaluator *Evaluator) getTerm(lexAn LexicalAnalyser, precedence uint, pare
 			// Process the lexer token.
 			switch lexAn.GetCurrentToken() {
 			case TokenOpPlus:
-				rightTerm, err := evaluator.getTerm(lexAn, precedence+1, parenthesesLevel)
+				operationError := newEvaluationError(nil, lexAn)
+				rightTerm, isPercentage, err := evaluator.getProductTerm(lexAn, precedence+1, parenthesesLevel)
 				if err != nil {
 					return 0.0, err
 				}
-				leftTerm += rightTerm
 
-			case TokenOpMinus:
-				rightTerm, err := evaluator.getTerm(lexAn, precedence+1, parenthesesLevel)
+				rightTerm = percentageOf(leftTerm, rightTerm, isPercentage)
+
+				leftTerm, err = evaluator.applyOperator(TokenOpPlus, leftTerm, rightTerm, operationError)
 				if err != nil {
 					return 0.0, err
 				}
-				leftTerm -= rightTerm
-
-			default:
-				return leftTerm, nil
-			}
-		}
-
-	case 2: // MULTIPLY, DIVIDE
-
-		// Process any higher operators first.
-		leftTerm, err := evaluator.getTerm(lexAn, precedence+1, parenthesesLevel)
-		if err != nil {
-			return 0.0, err
-		}
 
-		for {
-			// Process the lexer token.
-			switch lexAn.GetCurrentToken() {
-			case TokenOpMultiply:
-				rightTerm, err := evaluator.getTerm(lexAn, precedence+1, parenthesesLevel)
+			case TokenOpMinus:
+				operationError := newEvaluationError(nil, lexAn)
+				rightTerm, isPercentage, err := evaluator.getProductTerm(lexAn, precedence+1, parenthesesLevel)
 				if err != nil {
 					return 0.0, err
 				}
 
-				leftTerm *= rightTerm
-
-			case TokenOpDivide:
-				{
-					rightTerm, err := evaluator.getTerm(lexAn, precedence+1, parenthesesLevel)
-					if err != nil {
-						return 0.0, err
-					}
-
-					// Prevent a divide by zero.
-					if rightTerm == 0.0 {
-						return 0.0, ErrDivideByZero
-					}
-
-					leftTerm /= rightTerm
-				}
-
-			default:
-				return leftTerm, nil
-			}
-		}
-
-	case 3: // POWER.
-
-		// Process any higher operators first.
-		leftTerm, err := evaluator.getTerm(lexAn, precedence+1, parenthesesLevel)
-		if err != nil {
-			return 0.0, err
-		}
+				rightTerm = percentageOf(leftTerm, rightTerm, isPercentage)
 
-		for {
-			// Process the lexer token.
-			switch lexAn.GetCurrentToken() {
-			case TokenOpPower:
-				p, err := evaluator.getTerm(lexAn, precedence+1, parenthesesLevel)
+				leftTerm, err = evaluator.applyOperator(TokenOpMinus, leftTerm, rightTerm, operationError)
 				if err != nil {
 					return 0.0, err
 				}
 
-				leftTerm = math.Pow(leftTerm, p)
-
 			default:
 				return leftTerm, nil
 			}
 		}
 
+	case 2, 3, 4: // MULTIPLY, DIVIDE, POWER, PERCENT.
+		term, _, err := evaluator.getProductTerm(lexAn, precedence, parenthesesLevel)
+		return term, err
+
 	default: // Get primary.
 
+		// Each primary is an iteration of the evaluation.
+		token := lexAn.ParseNextToken()
+		if err := evaluator.iterate(lexAn); err != nil {
+			return 0.0, err
+		}
+
 		// Process the lexer token.
-		switch lexAn.ParseNextToken() {
+		switch token {
 		case TokenNumber:
 			{
+				if err := evaluator.checkNumber(lexAn); err != nil {
+					return 0.0, err
+				}
+
 				// Store the extracted number.
 				v := lexAn.GetNumericValue()
 
@@ -170,32 +286,94 @@ func (evaluator *Evaluator) getTerm(lexAn LexicalAnalyser, precedence uint, pare
 			}
 
 		case TokenOpMinus:
+			if err := evaluator.enterNesting(lexAn); err != nil {
+				return 0.0, err
+			}
+			defer evaluator.leaveNesting()
+
 			v, err := evaluator.getTerm(lexAn, precedence, parenthesesLevel)
 			return -v, err
 
 		case TokenOpPlus:
+			if err := evaluator.enterNesting(lexAn); err != nil {
+				return 0.0, err
+			}
+			defer evaluator.leaveNesting()
+
 			return evaluator.getTerm(lexAn, precedence, parenthesesLevel)
 
 		case TokenVariable:
 			//// Extract symbol value from global symbol table.
 			variableName := lexAn.GetTextValue()
-			variableValue := evaluator.VariableStore[variableName]
-			var err error = nil
+			_, isHistory := parseHistoryVariable(variableName)
+
+			// Keep the position of the variable for reporting an undefined variable.
+			variableError := newEvaluationError(nil, lexAn)
 
 			// Get the next token, so that the token type of the next token is available to the caller of this function.
 			// If we have an assign "=" then process the terms after the assign to determine the value of the symbol.
-			if lexAn.ParseNextToken() == TokenOpAssign {
-				variableValue, err = evaluator.getTerm(lexAn, 0, 0)
-				if err == nil {
-					evaluator.VariableStore[variableName] = variableValue
+			nextToken := lexAn.ParseNextToken()
+			if nextToken == TokenLBracket && variableName == ansVariable {
+				return evaluator.getIndexedResult(lexAn)
+			}
+
+			if nextToken == TokenOpAssign {
+				if isHistory {
+					return 0.0, newEvaluationError(ErrHistoryReadOnly, lexAn)
+				}
+
+				if evaluator.IsConstant(variableName) {
+					variableError.Err = ErrConstantAssignment
+					variableError.Name = variableName
+					return 0.0, variableError
+				}
+
+				if err := evaluator.enterNesting(lexAn); err != nil {
+					return 0.0, err
 				}
+				defer evaluator.leaveNesting()
+
+				variableValue, err := evaluator.getTerm(lexAn, 0, 0)
+				if err != nil {
+					return 0.0, err
+				}
+
+				if err := evaluator.Variables.SetVariable(variableName, variableValue); err != nil {
+					variableError.Err = err
+					variableError.Name = variableName
+					return 0.0, variableError
+				}
+
+				return variableValue, nil
 			}
 
 			// Return the value of the symbol.
-			return variableValue, err
+			return evaluator.getVariableValue(variableName, variableError)
+
+		case TokenIdentifier:
+			// An identifier is a special value literal or a function call, e.g. "hypot(3, 4)".
+			name := lexAn.GetTextValue()
+			if value, found := specialValueLiterals[name]; found {
+				lexAn.ParseNextToken()
+				return value, nil
+			}
+
+			functionError := newEvaluationError(nil, lexAn)
+			if lexAn.ParseNextToken() == TokenLParen {
+				return evaluator.getFunctionCall(name, lexAn, parenthesesLevel, functionError)
+			}
+
+			functionError.Err = ErrPrimaryExpected
+			functionError.Expected = expectedPrimaries
+			return 0.0, functionError
 
 		case TokenLParen:
 			{
+				if err := evaluator.enterNesting(lexAn); err != nil {
+					return 0.0, err
+				}
+				defer evaluator.leaveNesting()
+
 				// Treat the expression after the parentheses as a new expression and evaluate.
 				parenResult, err := evaluator.getTerm(lexAn, 0, parenthesesLevel+1)
 				if err != nil {
@@ -204,7 +382,7 @@ func (evaluator *Evaluator) getTerm(lexAn LexicalAnalyser, precedence uint, pare
 
 				// Check expression should have ended on a right parentheses.
 				if lexAn.GetCurrentToken() != TokenRParen {
-					return 0.0, ErrMissingClosingParentheses
+					return 0.0, newEvaluationError(ErrMissingClosingParentheses, lexAn, TokenRParen)
 				}
 
 				lexAn.ParseNextToken()
@@ -214,10 +392,190 @@ func (evaluator *Evaluator) getTerm(lexAn LexicalAnalyser, precedence uint, pare
 			}
 
 		case TokenBad:
-			return 0.0, ErrSyntax
+			return 0.0, newEvaluationError(ErrSyntax, lexAn, expectedPrimaries...)
 
 		default:
-			return 0.0, ErrPrimaryExpected
+			return 0.0, newEvaluationError(ErrPrimaryExpected, lexAn, expectedPrimaries...)
 		}
 	}
 }
+
+// Gets a term of the multiply and divide, power or percent precedence, and reports whether the whole term is a
+// percentage, e.g. "10%" but not "2 * 10%" or "(10%)".
+func (evaluator *Evaluator) getProductTerm(lexAn LexicalAnalyser, precedence uint,
+	parenthesesLevel uint) (float64, bool, error) {
+	// Process the terms at the supplied precedence.
+	switch precedence {
+	case 2: // MULTIPLY, DIVIDE
+
+		// Process any higher operators first.
+		leftTerm, isPercentage, err := evaluator.getProductTerm(lexAn, precedence+1, parenthesesLevel)
+		if err != nil {
+			return 0.0, false, err
+		}
+
+		for {
+			// Process the lexer token.
+			switch lexAn.GetCurrentToken() {
+			case TokenOpMultiply:
+				operationError := newEvaluationError(nil, lexAn)
+				rightTerm, _, err := evaluator.getProductTerm(lexAn, precedence+1, parenthesesLevel)
+				if err != nil {
+					return 0.0, false, err
+				}
+
+				leftTerm, err = evaluator.applyOperator(TokenOpMultiply, leftTerm, rightTerm, operationError)
+				if err != nil {
+					return 0.0, false, err
+				}
+				isPercentage = false
+
+			case TokenOpDivide:
+				// Keep the position of the operator for reporting a divide by zero.
+				operationError := newEvaluationError(nil, lexAn)
+				rightTerm, _, err := evaluator.getProductTerm(lexAn, precedence+1, parenthesesLevel)
+				if err != nil {
+					return 0.0, false, err
+				}
+
+				leftTerm, err = evaluator.applyOperator(TokenOpDivide, leftTerm, rightTerm, operationError)
+				if err != nil {
+					return 0.0, false, err
+				}
+				isPercentage = false
+
+			default:
+				return leftTerm, isPercentage, nil
+			}
+		}
+
+	case 3: // POWER.
+
+		// Process any higher operators first.
+		leftTerm, isPercentage, err := evaluator.getProductTerm(lexAn, precedence+1, parenthesesLevel)
+		if err != nil {
+			return 0.0, false, err
+		}
+
+		for {
+			// Process the lexer token.
+			switch lexAn.GetCurrentToken() {
+			case TokenOpPower:
+				operationError := newEvaluationError(nil, lexAn)
+				p, _, err := evaluator.getProductTerm(lexAn, precedence+1, parenthesesLevel)
+				if err != nil {
+					return 0.0, false, err
+				}
+
+				leftTerm, err = evaluator.applyOperator(TokenOpPower, leftTerm, p, operationError)
+				if err != nil {
+					return 0.0, false, err
+				}
+				isPercentage = false
+
+			default:
+				return leftTerm, isPercentage, nil
+			}
+		}
+
+	case 4: // PERCENT.
+
+		// Process the primary first.
+		leftTerm, err := evaluator.getTerm(lexAn, precedence+1, parenthesesLevel)
+		if err != nil {
+			return 0.0, false, err
+		}
+
+		if lexAn.GetCurrentToken() == TokenPercent {
+			lexAn.ParseNextToken()
+			return leftTerm / 100.0, true, nil
+		}
+
+		return leftTerm, false, nil
+
+	default: // Get primary.
+		term, err := evaluator.getTerm(lexAn, precedence, parenthesesLevel)
+		return term, false, err
+	}
+}
+
+// Gets the value of a variable, or of a history variable such as "$_3".  The variable error holds the position of the
+// variable and is returned if it is not defined.
+func (evaluator *Evaluator) getVariableValue(variableName string, variableError *EvaluationError) (float64, error) {
+	if number, isHistory := parseHistoryVariable(variableName); isHistory {
+		entry, found := evaluator.GetHistoryEntry(number)
+		if !found || number <= 0 {
+			variableError.Err = ErrHistoryNotFound
+			variableError.Name = variableName
+			return 0.0, variableError
+		}
+		return entry.Result, nil
+	}
+
+	variableValue, variableFound := evaluator.Variables.GetVariable(variableName)
+	if !variableFound && evaluator.Strict {
+		variableError.Err = ErrUndefinedVariable
+		variableError.Name = variableName
+		variableError.Suggestions = suggestNames(variableName, evaluator.Variables.GetVariableNames())
+		return 0.0, variableError
+	}
+
+	return variableValue, nil
+}
+
+// Gets the value of the right term of an addition or subtraction.  A right term that is a percentage, e.g. the "10%" in
+// "200 + 10%", is a percentage of the left term, as on a calculator.
+func percentageOf(leftTerm float64, rightTerm float64, isPercentage bool) float64 {
+	if isPercentage {
+		return leftTerm * rightTerm
+	}
+	return rightTerm
+}
+
+// Applies a binary operator to the terms.  The operation error holds the position of the operator, and is returned for
+// a divide by zero or when the non-finite policy rejects the result.
+func (evaluator *Evaluator) applyOperator(operator LexAnToken, leftTerm float64, rightTerm float64,
+	operationError *EvaluationError) (float64, error) {
+	switch operator {
+	case TokenOpPlus:
+		return evaluator.checkOperation(leftTerm+rightTerm, leftTerm, rightTerm, "addition", operationError)
+	case TokenOpMinus:
+		return evaluator.checkOperation(leftTerm-rightTerm, leftTerm, rightTerm, "subtraction", operationError)
+	case TokenOpMultiply:
+		return evaluator.checkOperation(leftTerm*rightTerm, leftTerm, rightTerm, "multiplication", operationError)
+	case TokenOpDivide:
+		// Prevent a divide by zero.
+		if rightTerm == 0.0 {
+			operationError.Err = ErrDivideByZero
+			return 0.0, operationError
+		}
+		return evaluator.checkOperation(leftTerm/rightTerm, leftTerm, rightTerm, "division", operationError)
+	case TokenOpPower:
+		return evaluator.checkOperation(math.Pow(leftTerm, rightTerm), leftTerm, rightTerm, "power", operationError)
+	default:
+		operationError.Err = ErrSyntax
+		return 0.0, operationError
+	}
+}
+
+// Applies the non-finite policy to the result of an operation.  Only operations on finite operands are checked, so that
+// infinite and NaN values entered as literals propagate.
+func (evaluator *Evaluator) checkOperation(result float64, leftTerm float64, rightTerm float64, operation string,
+	operationError *EvaluationError) (float64, error) {
+	if evaluator.NonFinitePolicy == NonFinitePropagate || isFinite(result) || !isFinite(leftTerm) || !isFinite(rightTerm) {
+		return result, nil
+	}
+
+	operationError.Operation = operation
+	if math.IsNaN(result) {
+		operationError.Err = ErrUndefinedResult
+	} else {
+		operationError.Err = ErrArithmeticOverflow
+	}
+
+	return 0.0, operationError
+}
+
+func isFinite(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
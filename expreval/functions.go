@@ -0,0 +1,159 @@
+package expreval
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+var ErrUndefinedFunction = errors.New("undefined function")
+var ErrFunctionArguments = errors.New("wrong number of arguments")
+var ErrFunctionExists = errors.New("function is already registered")
+var ErrInvalidFunctionName = errors.New("invalid function name")
+
+// Arity of a function that takes any number of arguments.
+const VariadicArity = -1
+
+// Function that can be called in an expression, e.g. "hypot(3, 4)".  An error returned by the function is reported at
+// the position of the call, naming the function.
+type Function func(arguments []float64) (float64, error)
+
+type registeredFunction struct {
+	arity    int
+	function Function
+}
+
+// Registers a function taking the number of arguments given by the arity, or any number for VariadicArity.  The name
+// must be an identifier that is not a literal, an RPN stack operation or an already registered function.  Contexts
+// already created from the evaluator do not have the function.  An evaluator used with a command parser should have its
+// functions registered through CommandParser.RegisterFunction, which also rejects the names of commands.
+func (evaluator *Evaluator) RegisterFunction(name string, arity int, function Function) error {
+	lexAn := CreateLexicalAnalyser(name)
+	if lexAn.ParseNextToken() != TokenIdentifier || lexAn.GetTextValue() != name || IsLiteral(name) ||
+		IsStackOperation(name) {
+		return fmt.Errorf("%w: %s", ErrInvalidFunctionName, name)
+	}
+
+	if evaluator.IsFunction(name) {
+		return fmt.Errorf("%w: %s", ErrFunctionExists, name)
+	}
+
+	if arity < VariadicArity || function == nil {
+		return fmt.Errorf("%w: %s", ErrFunctionArguments, name)
+	}
+
+	// The functions are copied, as the map may be shared with contexts evaluating in other goroutines.
+	functions := make(map[string]registeredFunction)
+	for functionName, registered := range evaluator.functions {
+		functions[functionName] = registered
+	}
+
+	functions[name] = registeredFunction{arity, function}
+	evaluator.functions = functions
+	return nil
+}
+
+// Reports whether the name is a registered function.
+func (evaluator *Evaluator) IsFunction(name string) bool {
+	_, found := evaluator.functions[name]
+	return found
+}
+
+// Gets the number of arguments a registered function takes, which is VariadicArity for any number.
+func (evaluator *Evaluator) GetFunctionArity(name string) (int, bool) {
+	registered, found := evaluator.functions[name]
+	return registered.arity, found
+}
+
+// Gets the names of the registered functions, sorted.
+func (evaluator *Evaluator) GetFunctionNames() []string {
+	names := []string{}
+	for name := range evaluator.functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Gets the arguments of a function call after the function name, up to and including the closing parenthesis, and
+// calls the function.  The function error holds the position of the function name.
+func (evaluator *Evaluator) getFunctionCall(name string, lexAn LexicalAnalyser, parenthesesLevel uint,
+	functionError *EvaluationError) (float64, error) {
+	registered, found := evaluator.functions[name]
+	if !found {
+		functionError.Err = ErrUndefinedFunction
+		functionError.Name = name
+		functionError.Suggestions = suggestNames(name, evaluator.GetFunctionNames())
+		return 0.0, functionError
+	}
+
+	if err := evaluator.enterNesting(lexAn); err != nil {
+		return 0.0, err
+	}
+	defer evaluator.leaveNesting()
+
+	arguments := []float64{}
+	if isEmptyArgumentList(lexAn.GetRemainingInput()) {
+		lexAn.ParseNextToken()
+	} else {
+		for lexAn.GetCurrentToken() != TokenRParen {
+			argument, err := evaluator.getTerm(lexAn, 0, parenthesesLevel+1)
+			if err != nil {
+				return 0.0, err
+			}
+
+			if lexAn.GetCurrentToken() != TokenComma && lexAn.GetCurrentToken() != TokenRParen {
+				return 0.0, newEvaluationError(ErrMissingClosingParentheses, lexAn, TokenRParen)
+			}
+
+			arguments = append(arguments, argument)
+		}
+	}
+
+	// Get the next token, so that the token type of the next token is available to the caller of this function.
+	lexAn.ParseNextToken()
+
+	return evaluator.callFunction(name, registered, arguments, functionError)
+}
+
+// Calls a function with the arguments, applying the non-finite policy to the result.
+func (evaluator *Evaluator) callFunction(name string, registered registeredFunction, arguments []float64,
+	functionError *EvaluationError) (float64, error) {
+	functionError.Operation = name
+	if registered.arity != VariadicArity && len(arguments) != registered.arity {
+		functionError.Err = ErrFunctionArguments
+		return 0.0, functionError
+	}
+
+	result, err := registered.function(arguments)
+	if err != nil {
+		functionError.Err = err
+		return 0.0, functionError
+	}
+
+	if evaluator.NonFinitePolicy == NonFinitePropagate || isFinite(result) {
+		return result, nil
+	}
+
+	// Only a function of finite arguments is checked, so that infinite and NaN values propagate.
+	for _, argument := range arguments {
+		if !isFinite(argument) {
+			return result, nil
+		}
+	}
+
+	if math.IsNaN(result) {
+		functionError.Err = ErrUndefinedResult
+	} else {
+		functionError.Err = ErrArithmeticOverflow
+	}
+
+	return 0.0, functionError
+}
+
+// Reports whether the input after an opening parenthesis closes it straight away, as in "f()".
+func isEmptyArgumentList(input string) bool {
+	lexAn := CreateLexicalAnalyser(input)
+	return lexAn.ParseNextToken() == TokenRParen
+}
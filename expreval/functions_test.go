@@ -0,0 +1,155 @@
+package expreval
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+var errNegativeArgument = errors.New("negative argument")
+
+// Creates an evaluator with the functions of an example extension.
+func newFunctionEvaluator(t *testing.T) *Evaluator {
+	evaluator := NewEvaluator()
+
+	functions := []struct {
+		name     string
+		arity    int
+		function Function
+	}{
+		{"hypot", 2, func(arguments []float64) (float64, error) {
+			return math.Hypot(arguments[0], arguments[1]), nil
+		}},
+		{"sqrt", 1, func(arguments []float64) (float64, error) {
+			if arguments[0] < 0 {
+				return 0, errNegativeArgument
+			}
+			return math.Sqrt(arguments[0]), nil
+		}},
+		{"sum", VariadicArity, func(arguments []float64) (float64, error) {
+			sum := 0.0
+			for _, argument := range arguments {
+				sum += argument
+			}
+			return sum, nil
+		}},
+		{"tau", 0, func(arguments []float64) (float64, error) {
+			return 2 * math.Pi, nil
+		}},
+		{"exp", 1, func(arguments []float64) (float64, error) {
+			return math.Exp(arguments[0]), nil
+		}},
+	}
+
+	for _, function := range functions {
+		if err := evaluator.RegisterFunction(function.name, function.arity, function.function); err != nil {
+			t.Fatal("Expected:", nil, "Actual:", err)
+		}
+	}
+
+	return evaluator
+}
+
+func TestEvaluateFunctions(t *testing.T) {
+	evaluator := newFunctionEvaluator(t)
+
+	result, err := evaluator.Evaluate("hypot(3, 4) * 2")
+	assertEvaluatedResult(t, 10, nil, result, err)
+
+	result, err = evaluator.Evaluate("hypot(sum(1, 1, 1), 2 ^ 2) + sum()")
+	assertEvaluatedResult(t, 5, nil, result, err)
+
+	result, err = evaluator.Evaluate("-sqrt((10 + 6)) * tau( )")
+	assertEvaluatedResult(t, -8*math.Pi, nil, result, err)
+
+	if !reflect.DeepEqual(evaluator.GetFunctionNames(), []string{"exp", "hypot", "sqrt", "sum", "tau"}) {
+		t.Error("Expected:", []string{"exp", "hypot", "sqrt", "sum", "tau"}, "Actual:", evaluator.GetFunctionNames())
+	}
+
+	arity, found := evaluator.GetFunctionArity("sum")
+	if arity != VariadicArity || !found {
+		t.Error("Expected:", VariadicArity, "Actual:", arity)
+	}
+}
+
+func TestEvaluateFunctionErrors(t *testing.T) {
+	evaluator := newFunctionEvaluator(t)
+
+	assertFunctionError(t, evaluator, "1 + hypot(3)", ErrFunctionArguments, 5)
+	assertFunctionError(t, evaluator, "1 + sqrt(0 - 4)", errNegativeArgument, 5)
+	assertFunctionError(t, evaluator, "exp(1000)", ErrArithmeticOverflow, 1)
+	assertFunctionError(t, evaluator, "hypot(3, 4", ErrMissingClosingParentheses, 11)
+	assertFunctionError(t, evaluator, "hypot(3, )", ErrPrimaryExpected, 10)
+	assertFunctionError(t, evaluator, "(3, 4)", ErrMissingClosingParentheses, 3)
+	assertFunctionError(t, evaluator, "3, 4", ErrSyntax, 2)
+
+	_, err := evaluator.Evaluate("1 + sqrt(0 - 4)")
+	if err.Error() != "negative argument in sqrt at column 5" {
+		t.Error("Expected:", "negative argument in sqrt at column 5", "Actual:", err)
+	}
+
+	_, err = evaluator.Evaluate("hypt(3, 4)")
+	if err.Error() != "undefined function hypt at column 1, did you mean hypot?" {
+		t.Error("Expected:", "undefined function hypt at column 1, did you mean hypot?", "Actual:", err)
+	}
+
+	// Infinite arguments propagate, as with the operators.
+	result, err := evaluator.Evaluate("exp(inf)")
+	assertEvaluatedResult(t, math.Inf(1), nil, result, err)
+}
+
+func TestRegisterFunctionErrors(t *testing.T) {
+	evaluator := newFunctionEvaluator(t)
+	function := func(arguments []float64) (float64, error) {
+		return 0, nil
+	}
+
+	err := evaluator.RegisterFunction("sum", 2, function)
+	if !errors.Is(err, ErrFunctionExists) {
+		t.Error("Expected:", ErrFunctionExists, "Actual:", err)
+	}
+
+	for _, name := range []string{"inf", "pi", "swap", "$x", "2x", "a b", ""} {
+		err := evaluator.RegisterFunction(name, 1, function)
+		if !errors.Is(err, ErrInvalidFunctionName) {
+			t.Error("Name:", name, "Expected:", ErrInvalidFunctionName, "Actual:", err)
+		}
+	}
+
+	err = evaluator.RegisterFunction("f", -2, function)
+	if !errors.Is(err, ErrFunctionArguments) {
+		t.Error("Expected:", ErrFunctionArguments, "Actual:", err)
+	}
+
+	if evaluator.IsFunction("f") {
+		t.Error("Function should not be registered: f")
+	}
+}
+
+func TestRPNStackFunctions(t *testing.T) {
+	stack := NewRPNStack(newFunctionEvaluator(t))
+
+	err := stack.Evaluate("1 3 4 hypot pi")
+	if err != nil || !reflect.DeepEqual(stack.GetValues(), []float64{1, 5, math.Pi}) {
+		t.Error("Expected:", []float64{1, 5, math.Pi}, "Actual:", stack.GetValues(), err)
+	}
+
+	err = stack.Evaluate("drop sum")
+	if err != nil || !reflect.DeepEqual(stack.GetValues(), []float64{6}) {
+		t.Error("Expected:", []float64{6}, "Actual:", stack.GetValues(), err)
+	}
+
+	assertRPNError(t, stack, "hypot", ErrStackUnderflow, 1)
+	assertRPNError(t, stack, "-1 sqrt", errNegativeArgument, 4)
+}
+
+func assertFunctionError(t *testing.T, evaluator *Evaluator, expression string, expectedError error,
+	expectedColumn int) {
+	_, err := evaluator.Evaluate(expression)
+
+	var evaluationError *EvaluationError
+	if !errors.As(err, &evaluationError) || !errors.Is(err, expectedError) || evaluationError.Column != expectedColumn {
+		t.Error("Expression:", expression, "Expected:", expectedError, "at column", expectedColumn, "Actual:", err)
+	}
+}
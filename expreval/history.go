@@ -0,0 +1,100 @@
+package expreval
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Default number of results kept in the history.
+	DefaultMaxHistory = 1000
+)
+
+var ErrHistoryNotFound = errors.New("history entry not found")
+var ErrHistoryReadOnly = errors.New("history entries cannot be assigned")
+
+// An evaluated expression and its result.
+type HistoryEntry struct {
+	Number     int
+	Expression string
+	Result     float64
+}
+
+// Gets the history entry with the number.  A negative number counts back from the latest entry, so -1 is the latest
+// and -2 the one before.
+func (evaluator *Evaluator) GetHistoryEntry(number int) (HistoryEntry, bool) {
+	history := evaluator.History
+	if len(history) == 0 {
+		return HistoryEntry{}, false
+	}
+
+	index := len(history) + number
+	if number > 0 {
+		index = number - history[0].Number
+	}
+
+	if index < 0 || index >= len(history) {
+		return HistoryEntry{}, false
+	}
+
+	return history[index], true
+}
+
+// Adds the result to the history, discarding the oldest entries beyond MaxHistory.  Entries are numbered from 1 and
+// keep their number when older entries are discarded.
+func (evaluator *Evaluator) addHistoryEntry(expression string, result float64) {
+	if evaluator.MaxHistory <= 0 {
+		return
+	}
+
+	number := 1
+	if len(evaluator.History) > 0 {
+		number = evaluator.History[len(evaluator.History)-1].Number + 1
+	}
+
+	evaluator.History = append(evaluator.History, HistoryEntry{number, strings.TrimSpace(expression), result})
+	if len(evaluator.History) > evaluator.MaxHistory {
+		evaluator.History = evaluator.History[len(evaluator.History)-evaluator.MaxHistory:]
+	}
+}
+
+// Gets the number of a history reference variable such as "$_3".
+func parseHistoryVariable(variableName string) (int, bool) {
+	if !strings.HasPrefix(variableName, "$_") {
+		return 0, false
+	}
+
+	number, err := strconv.Atoi(variableName[2:])
+	return number, err == nil
+}
+
+// Gets the result referenced by the index in "$ans[3]" or "$ans[-2]".  The lexer is positioned on the "[" and is left
+// on the token after the "]".
+func (evaluator *Evaluator) getIndexedResult(lexAn LexicalAnalyser) (float64, error) {
+	sign := 1.0
+	if lexAn.ParseNextToken() == TokenOpMinus {
+		sign = -1.0
+		lexAn.ParseNextToken()
+	}
+
+	if lexAn.GetCurrentToken() != TokenNumber {
+		return 0.0, newEvaluationError(ErrSyntax, lexAn, TokenNumber)
+	}
+
+	indexError := newEvaluationError(ErrHistoryNotFound, lexAn)
+	index := sign * lexAn.GetNumericValue()
+
+	if lexAn.ParseNextToken() != TokenRBracket {
+		return 0.0, newEvaluationError(ErrSyntax, lexAn, TokenRBracket)
+	}
+	lexAn.ParseNextToken()
+
+	entry, found := evaluator.GetHistoryEntry(int(index))
+	if !found || index != float64(int(index)) {
+		indexError.Name = "$ans[" + strconv.FormatFloat(index, 'f', -1, 64) + "]"
+		return 0.0, indexError
+	}
+
+	return entry.Result, nil
+}
@@ -0,0 +1,93 @@
+package expreval
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEvaluateAddsHistory(t *testing.T) {
+	evaluator := NewEvaluator()
+	evaluator.Evaluate("1 + 2")
+	evaluator.Evaluate("$a = 5")
+	evaluator.Evaluate("1 / 0")
+
+	if len(evaluator.History) != 2 {
+		t.Fatal("Expected:", 2, "Actual:", len(evaluator.History))
+	}
+
+	assertHistoryEntry(t, evaluator, 1, HistoryEntry{1, "1 + 2", 3})
+	assertHistoryEntry(t, evaluator, 2, HistoryEntry{2, "$a = 5", 5})
+	assertHistoryEntry(t, evaluator, -1, HistoryEntry{2, "$a = 5", 5})
+	assertHistoryEntry(t, evaluator, -2, HistoryEntry{1, "1 + 2", 3})
+}
+
+func TestEvaluateHistoryIsBounded(t *testing.T) {
+	evaluator := NewEvaluator()
+	evaluator.MaxHistory = 2
+	evaluator.Evaluate("1")
+	evaluator.Evaluate("2")
+	evaluator.Evaluate("3")
+
+	if len(evaluator.History) != 2 {
+		t.Fatal("Expected:", 2, "Actual:", len(evaluator.History))
+	}
+
+	assertHistoryEntry(t, evaluator, 2, HistoryEntry{2, "2", 2})
+	assertHistoryEntry(t, evaluator, 3, HistoryEntry{3, "3", 3})
+	if _, found := evaluator.GetHistoryEntry(1); found {
+		t.Error("Expected:", "entry 1 discarded")
+	}
+
+	// Disabling the history.
+	evaluator.MaxHistory = 0
+	evaluator.History = []HistoryEntry{}
+	evaluator.Evaluate("4")
+	if len(evaluator.History) != 0 {
+		t.Error("Expected:", 0, "Actual:", len(evaluator.History))
+	}
+}
+
+func TestEvaluateHistoryReferences(t *testing.T) {
+	evaluator := NewEvaluator()
+	evaluator.Evaluate("10")
+	evaluator.Evaluate("20")
+	evaluator.Evaluate("30")
+
+	result, err := evaluator.Evaluate("$ans[1] + $_2")
+	assertEvaluatedResult(t, 30, nil, result, err)
+
+	// Relative to the latest entry, which is now 30 from the last evaluation.
+	result, err = evaluator.Evaluate("$ans[-1] * 2 - $ans[ -3 ]")
+	assertEvaluatedResult(t, 40, nil, result, err)
+}
+
+func TestEvaluateHistoryReferenceErrors(t *testing.T) {
+	evaluator := NewEvaluator()
+	evaluator.Evaluate("10")
+
+	result, err := evaluator.Evaluate("$ans[2]")
+	assertEvaluatedResult(t, 0, ErrHistoryNotFound, result, err)
+	result, err = evaluator.Evaluate("$_0")
+	assertEvaluatedResult(t, 0, ErrHistoryNotFound, result, err)
+	result, err = evaluator.Evaluate("$ans[1.5]")
+	assertEvaluatedResult(t, 0, ErrHistoryNotFound, result, err)
+	result, err = evaluator.Evaluate("$ans[1")
+	assertEvaluatedResult(t, 0, ErrSyntax, result, err)
+	result, err = evaluator.Evaluate("$ans[$x]")
+	assertEvaluatedResult(t, 0, ErrSyntax, result, err)
+	result, err = evaluator.Evaluate("$_1 = 5")
+	assertEvaluatedResult(t, 0, ErrHistoryReadOnly, result, err)
+
+	var evaluationError *EvaluationError
+	_, err = evaluator.Evaluate("1 + $_7")
+	if !errors.As(err, &evaluationError) || evaluationError.Name != "$_7" || evaluationError.Column != 5 {
+		t.Error("Expected:", "history entry not found $_7 at column 5", "Actual:", err)
+	}
+}
+
+func assertHistoryEntry(t *testing.T, evaluator *Evaluator, number int, expectedEntry HistoryEntry) {
+	entry, found := evaluator.GetHistoryEntry(number)
+	if !found || entry != expectedEntry {
+		t.Error("Number:", number, "Expected:", expectedEntry, "Actual:", entry, found)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 var ErrGeneral = errors.New("general error reading input")
@@ -45,6 +46,14 @@ const (
 	TokenNumber
 	// Identifier.
 	TokenIdentifier
+	// Left bracket "[".
+	TokenLBracket
+	// Right bracket "]".
+	TokenRBracket
+	// Percent "%".
+	TokenPercent
+	// Comma ",".
+	TokenComma
 )
 
 //go:generate stringer -type=BaseModifier
@@ -67,6 +76,10 @@ type LexicalAnalyser interface {
 	GetTextValue() string
 	// Gets the numeric value of the current token.
 	GetNumericValue() float64
+	// Gets the input that has not yet been parsed.
+	GetRemainingInput() string
+	// Gets the byte offsets of the start and end of the current token within the input.
+	GetTokenSpan() (int, int)
 }
 
 // Lexical Analyser implementation that uses io.Reader.
@@ -76,6 +89,8 @@ type LexicalAnalyserReaderImpl struct {
 	currentToken LexAnToken
 	textValue    string
 	numericValue float64
+	tokenStart   int
+	tokenEnd     int
 }
 
 var binRangeTable = &unicode.RangeTable{
@@ -105,6 +120,13 @@ func CreateLexicalAnalyser(input string) LexicalAnalyser {
 }
 
 func (lexAn *LexicalAnalyserReaderImpl) ParseNextToken() LexAnToken {
+	// Record the end of the token once it has been parsed.
+	defer func() {
+		lexAn.tokenEnd = lexAn.offset()
+	}()
+
+	// The end of the input is positioned straight after the previous token, ignoring trailing whitespace.
+	lexAn.tokenStart = lexAn.offset()
 
 	c, err := nextCharacterIgnoringWhitespace(lexAn.reader)
 	if err != nil {
@@ -117,11 +139,21 @@ func (lexAn *LexicalAnalyserReaderImpl) ParseNextToken() LexAnToken {
 		return lexAn.currentToken
 	}
 
+	lexAn.tokenStart = lexAn.offset() - utf8.RuneLen(c)
+
 	switch c {
 	case '(':
 		lexAn.currentToken = TokenLParen
 	case ')':
 		lexAn.currentToken = TokenRParen
+	case '[':
+		lexAn.currentToken = TokenLBracket
+	case ']':
+		lexAn.currentToken = TokenRBracket
+	case '%':
+		lexAn.currentToken = TokenPercent
+	case ',':
+		lexAn.currentToken = TokenComma
 	case '=':
 		lexAn.currentToken = TokenOpAssign
 	case '+':
@@ -136,6 +168,10 @@ func (lexAn *LexicalAnalyserReaderImpl) ParseNextToken() LexAnToken {
 		lexAn.currentToken = TokenOpPower
 	case '$':
 		var identifier, err = parserIdentifier(lexAn.reader, "")
+		if err == ErrIdentifierSyntax {
+			// A history reference, e.g. "$_3".
+			identifier, err = parseHistoryReference(lexAn.reader)
+		}
 		if err != nil {
 			lexAn.currentToken = TokenBad
 			lexAn.textValue = ""
@@ -180,7 +216,7 @@ func (lexAn *LexicalAnalyserReaderImpl) ParseNextToken() LexAnToken {
 	default:
 		// An idenitifier or bad token.
 		if unicode.IsLetter(c) {
-			symbolChar, _, _ := lexAn.reader.ReadRune()
+			symbolChar, _, symbolErr := lexAn.reader.ReadRune()
 
 			preReadfragment := string(c) + string(symbolChar)
 			baseModifier := extractNumberBaseModifier(preReadfragment)
@@ -197,7 +233,12 @@ func (lexAn *LexicalAnalyserReaderImpl) ParseNextToken() LexAnToken {
 					lexAn.numericValue = number
 				}
 			} else {
-				identifier, err := parserIdentifier(lexAn.reader, preReadfragment)
+				// Only the first letter is part of the identifier so far.
+				if symbolErr == nil {
+					lexAn.reader.UnreadRune()
+				}
+
+				identifier, err := parserIdentifier(lexAn.reader, string(c))
 				if err != nil {
 					lexAn.currentToken = TokenBad
 					lexAn.textValue = ""
@@ -230,6 +271,19 @@ func (lexAn *LexicalAnalyserReaderImpl) GetNumericValue() float64 {
 	return lexAn.numericValue
 }
 
+func (lexAn *LexicalAnalyserReaderImpl) GetRemainingInput() string {
+	return lexAn.input[lexAn.offset():]
+}
+
+func (lexAn *LexicalAnalyserReaderImpl) GetTokenSpan() (int, int) {
+	return lexAn.tokenStart, lexAn.tokenEnd
+}
+
+// Gets the byte offset of the next character to be read from the input.
+func (lexAn *LexicalAnalyserReaderImpl) offset() int {
+	return len(lexAn.input) - lexAn.reader.Len()
+}
+
 func nextCharacterIgnoringWhitespace(reader *strings.Reader) (rune, error) {
 	var c rune
 	var err error
@@ -339,8 +393,37 @@ func extractNumberBaseModifier(modifier string) BaseModifier {
 	}
 }
 
+// Parses the "_" and number of a history reference.
+func parseHistoryReference(reader *strings.Reader) (string, error) {
+	c, _, err := reader.ReadRune()
+	if err != nil || c != '_' {
+		return "", ErrIdentifierSyntax
+	}
+
+	number := ""
+	for {
+		c, _, err := reader.ReadRune()
+		if err != nil {
+			break
+		}
+
+		if !unicode.IsDigit(c) {
+			reader.UnreadRune()
+			break
+		}
+
+		number += string(c)
+	}
+
+	if len(number) == 0 {
+		return "", ErrIdentifierSyntax
+	}
+
+	return "_" + number, nil
+}
+
 func parserIdentifier(reader *strings.Reader, preReadFragment string) (string, error) {
-	haveFirstLetter := false
+	haveFirstLetter := len(preReadFragment) > 0
 	identifer := preReadFragment
 
 	for {
@@ -13,6 +13,7 @@ func TestParseNextTokenOperators(t *testing.T) {
 	assertNextToken(t, CreateLexicalAnalyser("*"), TokenOpMultiply)
 	assertNextToken(t, CreateLexicalAnalyser("/"), TokenOpDivide)
 	assertNextToken(t, CreateLexicalAnalyser("^"), TokenOpPower)
+	assertNextToken(t, CreateLexicalAnalyser(","), TokenComma)
 }
 
 func TestParseNextTokenNumber(t *testing.T) {
@@ -63,22 +64,36 @@ func TestParseNextTokenHexNumber(t *testing.T) {
 func TestParseNextTokenVariable(t *testing.T) {
 	assertNextTokenValue(t, CreateLexicalAnalyser("$variablename"), TokenVariable, 0.0, "$variablename")
 	assertNextTokenValue(t, CreateLexicalAnalyser("$"), TokenBad, 0.0, "")
+	assertNextTokenValue(t, CreateLexicalAnalyser("$_12"), TokenVariable, 0.0, "$_12")
+	assertNextTokenValue(t, CreateLexicalAnalyser("$_"), TokenBad, 0.0, "")
 }
 
 func TestParseNextTokenIdenitfier(t *testing.T) {
 	assertNextTokenValue(t, CreateLexicalAnalyser("identifier"), TokenIdentifier, 0.0, "identifier")
+	assertNextTokenValue(t, CreateLexicalAnalyser("x"), TokenIdentifier, 0.0, "x")
+	assertNextTokenValue(t, CreateLexicalAnalyser("h = hex"), TokenIdentifier, 0.0, "h")
+	assertNextTokenValue(t, CreateLexicalAnalyser("sc 2"), TokenIdentifier, 0.0, "sc")
+	assertNextTokenValue(t, CreateLexicalAnalyser("a2+"), TokenIdentifier, 0.0, "a2")
+
+	lexAn := CreateLexicalAnalyser("on off")
+	assertNextTokenValue(t, lexAn, TokenIdentifier, 0.0, "on")
+	assertNextTokenValue(t, lexAn, TokenIdentifier, 0.0, "off")
+	assertNextToken(t, lexAn, TokenEnd)
 }
 
 func TestParseNextTokenMultipleTokens(t *testing.T) {
-	lexAn := CreateLexicalAnalyser("()=+-*/^$abcdefg 1234567890")
+	lexAn := CreateLexicalAnalyser("()[]=+-*/^%$abcdefg 1234567890")
 	assertNextToken(t, lexAn, TokenLParen)
 	assertNextToken(t, lexAn, TokenRParen)
+	assertNextToken(t, lexAn, TokenLBracket)
+	assertNextToken(t, lexAn, TokenRBracket)
 	assertNextToken(t, lexAn, TokenOpAssign)
 	assertNextToken(t, lexAn, TokenOpPlus)
 	assertNextToken(t, lexAn, TokenOpMinus)
 	assertNextToken(t, lexAn, TokenOpMultiply)
 	assertNextToken(t, lexAn, TokenOpDivide)
 	assertNextToken(t, lexAn, TokenOpPower)
+	assertNextToken(t, lexAn, TokenPercent)
 	assertNextTokenValue(t, lexAn, TokenVariable, 0.0, "$abcdefg")
 	assertNextTokenValue(t, lexAn, TokenNumber, 1234567890.0, "")
 	assertNextToken(t, lexAn, TokenEnd)
@@ -114,3 +129,31 @@ func assertNextTokenValue(t *testing.T, lexAn LexicalAnalyser, expectedToken Lex
 		t.Error("Expected:", expectedNumericValue, "Actual:", numericValue)
 	}
 }
+
+func TestGetRemainingInput(t *testing.T) {
+	lexAn := CreateLexicalAnalyser("plot $x ^ 2, $x, 0, 1")
+	assertNextTokenValue(t, lexAn, TokenIdentifier, 0.0, "plot")
+
+	remainingInput := lexAn.GetRemainingInput()
+	if remainingInput != " $x ^ 2, $x, 0, 1" {
+		t.Error("Expected:", " $x ^ 2, $x, 0, 1", "Actual:", remainingInput)
+	}
+}
+
+func TestGetTokenSpan(t *testing.T) {
+	lexAn := CreateLexicalAnalyser(" 12.5 *£ $abc")
+	assertNextTokenSpan(t, lexAn, TokenNumber, 1, 5)
+	assertNextTokenSpan(t, lexAn, TokenOpMultiply, 6, 7)
+	assertNextTokenSpan(t, lexAn, TokenBad, 7, 9)
+	assertNextTokenSpan(t, lexAn, TokenVariable, 10, 14)
+	assertNextTokenSpan(t, lexAn, TokenEnd, 14, 14)
+}
+
+func assertNextTokenSpan(t *testing.T, lexAn LexicalAnalyser, expectedToken LexAnToken, expectedStart int, expectedEnd int) {
+	assertNextToken(t, lexAn, expectedToken)
+
+	start, end := lexAn.GetTokenSpan()
+	if start != expectedStart || end != expectedEnd {
+		t.Error("Expected:", expectedStart, expectedEnd, "Actual:", start, end)
+	}
+}
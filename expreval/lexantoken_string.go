@@ -21,11 +21,15 @@ func _() {
 	_ = x[TokenVariable-10]
 	_ = x[TokenNumber-11]
 	_ = x[TokenIdentifier-12]
+	_ = x[TokenLBracket-13]
+	_ = x[TokenRBracket-14]
+	_ = x[TokenPercent-15]
+	_ = x[TokenComma-16]
 }
 
-const _LexAnToken_name = "TokenBadTokenEndTokenLParenTokenRParenTokenOpAssignTokenOpPlusTokenOpMinusTokenOpMultiplyTokenOpDivideTokenOpPowerTokenVariableTokenNumberTokenIdentifier"
+const _LexAnToken_name = "TokenBadTokenEndTokenLParenTokenRParenTokenOpAssignTokenOpPlusTokenOpMinusTokenOpMultiplyTokenOpDivideTokenOpPowerTokenVariableTokenNumberTokenIdentifierTokenLBracketTokenRBracketTokenPercentTokenComma"
 
-var _LexAnToken_index = [...]uint8{0, 8, 16, 27, 38, 51, 62, 74, 89, 102, 114, 127, 138, 153}
+var _LexAnToken_index = [...]uint8{0, 8, 16, 27, 38, 51, 62, 74, 89, 102, 114, 127, 138, 153, 166, 179, 191, 201}
 
 func (i LexAnToken) String() string {
 	if i < 0 || i >= LexAnToken(len(_LexAnToken_index)-1) {
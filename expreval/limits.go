@@ -0,0 +1,125 @@
+package expreval
+
+import (
+	"context"
+	"errors"
+	"unicode/utf8"
+)
+
+// The expression is longer than Limits.MaxLength.
+var ErrExpressionTooLong = errors.New("expression is too long")
+
+// The parentheses, function calls, signs or assignments are nested more deeply than Limits.MaxNesting.
+var ErrNestingTooDeep = errors.New("expression is nested too deeply")
+
+// The evaluation took more iterations than Limits.MaxIterations.
+var ErrTooManyIterations = errors.New("evaluation has too many iterations")
+
+// A number has more characters than Limits.MaxNumberLength.
+var ErrNumberTooLong = errors.New("number is too long")
+
+// Evaluations started by functions while evaluating are nested more deeply than Limits.MaxRecursion.
+var ErrRecursionTooDeep = errors.New("evaluation recursion is too deep")
+
+const (
+	// Default maximum nesting, which keeps deeply nested input from exhausting the stack.
+	DefaultMaxNesting = 1000
+	// Default maximum depth of evaluations started by functions.
+	DefaultMaxRecursion = 16
+)
+
+// Limits on the work done to evaluate an expression, e.g. one entered by a user of a service.  A limit of zero means
+// that there is no limit.  Exceeding a limit returns an EvaluationError with the limit's error, positioned where the
+// limit was exceeded.
+type Limits struct {
+	// Maximum length of an expression in bytes.
+	MaxLength int
+	// Maximum nesting of parentheses, function calls, signs and assignments, e.g. "$b = -(-(1))" is nested five deep.
+	MaxNesting int
+	// Maximum number of iterations of an evaluation, where each number, variable, parenthesis and function call in an
+	// expression, and each value or operation of RPN input, is an iteration.
+	MaxIterations int
+	// Maximum number of characters in a number, including any base modifier, e.g. "h$ff" has four.
+	MaxNumberLength int
+	// Maximum depth of the evaluations started by functions, e.g. a function that evaluates another expression.
+	MaxRecursion int
+}
+
+// Limits used by NewEvaluator, which only limit the nesting and recursion.
+var DefaultLimits = Limits{MaxNesting: DefaultMaxNesting, MaxRecursion: DefaultMaxRecursion}
+
+// State of an evaluation that is in progress, for checking the limits.
+type evaluationState struct {
+	ctx context.Context
+	// Depth of this evaluation, starting at 1, when it is started by a function while evaluating.
+	recursion  int
+	nesting    int
+	iterations int
+}
+
+// Starts an evaluation of the expression, checking its length, the recursion limit and whether the context is done, and
+// returns a function that ends it.  An evaluation that cannot start is reported for the whole expression.
+func (evaluator *Evaluator) startEvaluation(ctx context.Context, expression string) (func(), error) {
+	if limit := evaluator.Limits.MaxLength; limit > 0 && len(expression) > limit {
+		limitError := &EvaluationError{Err: ErrExpressionTooLong, Start: limit, End: len(expression)}
+		limitError.Column = utf8.RuneCountInString(expression[:limit]) + 1
+		return nil, limitError
+	}
+
+	state := &evaluationState{ctx: ctx, recursion: 1}
+	previousState := evaluator.state
+	if previousState != nil {
+		state.recursion = previousState.recursion + 1
+	}
+
+	if limit := evaluator.Limits.MaxRecursion; limit > 0 && state.recursion > limit {
+		return nil, &EvaluationError{Err: ErrRecursionTooDeep, End: len(expression), Column: 1}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, &EvaluationError{Err: err, End: len(expression), Column: 1}
+	}
+
+	evaluator.state = state
+	return func() {
+		evaluator.state = previousState
+	}, nil
+}
+
+// Counts an iteration of the evaluation at the current token, checking the iteration limit and whether the context
+// is done.
+func (evaluator *Evaluator) iterate(lexAn LexicalAnalyser) error {
+	state := evaluator.state
+	state.iterations++
+	if limit := evaluator.Limits.MaxIterations; limit > 0 && state.iterations > limit {
+		return newEvaluationError(ErrTooManyIterations, lexAn)
+	}
+
+	if err := state.ctx.Err(); err != nil {
+		return newEvaluationError(err, lexAn)
+	}
+
+	return nil
+}
+
+// Enters a level of nesting, checking the nesting limit.
+func (evaluator *Evaluator) enterNesting(lexAn LexicalAnalyser) error {
+	evaluator.state.nesting++
+	if limit := evaluator.Limits.MaxNesting; limit > 0 && evaluator.state.nesting > limit {
+		return newEvaluationError(ErrNestingTooDeep, lexAn)
+	}
+	return nil
+}
+
+func (evaluator *Evaluator) leaveNesting() {
+	evaluator.state.nesting--
+}
+
+// Checks the length of the number that is the current token.
+func (evaluator *Evaluator) checkNumber(lexAn LexicalAnalyser) error {
+	start, end := lexAn.GetTokenSpan()
+	if limit := evaluator.Limits.MaxNumberLength; limit > 0 && end-start > limit {
+		return newEvaluationError(ErrNumberTooLong, lexAn)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package expreval
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEvaluateNestingLimit(t *testing.T) {
+	evaluator := NewEvaluator()
+	evaluator.Evaluate("$a = 1")
+
+	// Deeply nested input is stopped before it exhausts the stack.
+	depth := DefaultMaxNesting * 100
+	for _, expression := range []string{strings.Repeat("(", depth) + "1", strings.Repeat("-", depth) + "1",
+		strings.Repeat("$a = ", depth) + "1"} {
+		_, err := evaluator.Evaluate(expression)
+		if !errors.Is(err, ErrNestingTooDeep) {
+			t.Error("Expected:", ErrNestingTooDeep, "Actual:", err)
+		}
+	}
+
+	evaluator.Limits.MaxNesting = 5
+	result, err := evaluator.Evaluate("$b = -(-(2)) + (1)")
+	assertEvaluatedResult(t, 3, nil, result, err)
+	assertLimitError(t, evaluator, "$b = -(-(+(1)))", ErrNestingTooDeep, 10)
+
+	evaluator.Limits.MaxNesting = 0
+	result, err = evaluator.Evaluate(strings.Repeat("(", 2000) + "1" + strings.Repeat(")", 2000))
+	assertEvaluatedResult(t, 1, nil, result, err)
+}
+
+func TestEvaluateLengthLimit(t *testing.T) {
+	evaluator := NewEvaluator()
+	evaluator.Limits.MaxLength = 10
+
+	result, err := evaluator.Evaluate("1 + 2 + 30")
+	assertEvaluatedResult(t, 33, nil, result, err)
+	assertLimitError(t, evaluator, "1 + 2 + 3 + 4", ErrExpressionTooLong, 11)
+
+	err = NewRPNStack(evaluator).Evaluate("1 2 + 3 4 + *")
+	if !errors.Is(err, ErrExpressionTooLong) {
+		t.Error("Expected:", ErrExpressionTooLong, "Actual:", err)
+	}
+}
+
+func TestEvaluateIterationLimit(t *testing.T) {
+	evaluator := NewEvaluator()
+	evaluator.Limits.MaxIterations = 3
+
+	result, err := evaluator.Evaluate("1 + 2 * 3")
+	assertEvaluatedResult(t, 7, nil, result, err)
+	assertLimitError(t, evaluator, "1 + 2 + 3 + 4", ErrTooManyIterations, 13)
+
+	stack := NewRPNStack(evaluator)
+	if err := stack.Evaluate("1 2 +"); err != nil {
+		t.Error("Expected:", nil, "Actual:", err)
+	}
+	assertRPNError(t, stack, "1 2 + 3", ErrTooManyIterations, 7)
+}
+
+func TestEvaluateNumberLengthLimit(t *testing.T) {
+	evaluator := NewEvaluator()
+	evaluator.Limits.MaxNumberLength = 5
+
+	result, err := evaluator.Evaluate("12345 + h$fff")
+	assertEvaluatedResult(t, 16440, nil, result, err)
+	assertLimitError(t, evaluator, "1 + 123456", ErrNumberTooLong, 5)
+	assertLimitError(t, evaluator, "1.23456", ErrNumberTooLong, 1)
+
+	assertRPNError(t, NewRPNStack(evaluator), "1 -123456", ErrNumberTooLong, 4)
+}
+
+func TestEvaluateRecursionLimit(t *testing.T) {
+	evaluator := NewEvaluator()
+	calls := 0
+	evaluator.RegisterFunction("again", 0, func(arguments []float64) (float64, error) {
+		calls++
+		return evaluator.Evaluate("again()")
+	})
+
+	_, err := evaluator.Evaluate("1 + again()")
+	if !errors.Is(err, ErrRecursionTooDeep) || calls != DefaultMaxRecursion {
+		t.Error("Expected:", ErrRecursionTooDeep, DefaultMaxRecursion, "Actual:", err, calls)
+	}
+
+	// The evaluation that is too deep is reported for the whole expression it was given.
+	evaluator.Limits.MaxRecursion = 1
+	evaluator.RegisterFunction("inner", 0, func(arguments []float64) (float64, error) {
+		_, err := evaluator.Evaluate("2 + 3")
+		return 0, err
+	})
+	_, err = evaluator.Evaluate("inner()")
+	var evaluationError *EvaluationError
+	if !errors.As(errors.Unwrap(err), &evaluationError) || evaluationError.Column != 1 || evaluationError.End != 5 {
+		t.Error("Expected:", ErrRecursionTooDeep, "at column 1", "Actual:", err)
+	}
+	evaluator.Limits.MaxRecursion = DefaultMaxRecursion
+
+	// The evaluator can be used again once the evaluation has ended.
+	result, err := evaluator.Evaluate("1 + 1")
+	assertEvaluatedResult(t, 2, nil, result, err)
+}
+
+func TestEvaluateContextCancelled(t *testing.T) {
+	evaluator := NewEvaluator()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The evaluation stops at the next iteration after the context is cancelled.
+	evaluator.RegisterFunction("cancel", 0, func(arguments []float64) (float64, error) {
+		cancel()
+		return 1, nil
+	})
+
+	_, err := evaluator.EvaluateContext(ctx, "1 + cancel() + 2")
+	var evaluationError *EvaluationError
+	if !errors.As(err, &evaluationError) || !errors.Is(err, context.Canceled) || evaluationError.Column != 16 {
+		t.Error("Expected:", context.Canceled, "at column 16", "Actual:", err)
+	}
+
+	_, err = evaluator.EvaluateContext(ctx, "1 + 2")
+	if !errors.As(err, &evaluationError) || !errors.Is(err, context.Canceled) || evaluationError.Column != 1 {
+		t.Error("Expected:", context.Canceled, "at column 1", "Actual:", err)
+	}
+
+	ctx, cancel = context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	err = NewRPNStack(evaluator).EvaluateContext(ctx, "1 2 +")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("Expected:", context.DeadlineExceeded, "Actual:", err)
+	}
+
+	if _, found := evaluator.Variables.GetVariable(ansVariable); found || len(evaluator.History) != 0 {
+		t.Error("Expected:", "no result", "Actual:", evaluator.History)
+	}
+}
+
+func assertLimitError(t *testing.T, evaluator *Evaluator, expression string, expectedError error, expectedColumn int) {
+	_, err := evaluator.Evaluate(expression)
+
+	var evaluationError *EvaluationError
+	if !errors.As(err, &evaluationError) || !errors.Is(err, expectedError) || evaluationError.Column != expectedColumn {
+		t.Error("Expression:", expression, "Expected:", expectedError, "at column", expectedColumn, "Actual:", err)
+	}
+}
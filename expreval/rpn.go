@@ -0,0 +1,277 @@
+package expreval
+
+import (
+	"context"
+	"errors"
+	"sort"
+)
+
+var ErrStackUnderflow = errors.New("too few values on the stack")
+var ErrUnknownOperation = errors.New("unknown operation")
+
+// Operation on the values at the top of an RPN stack, such as "swap".
+type stackOperation struct {
+	// Number of values the operation uses.
+	operands int
+	// Returns the stack after the operation.  The top of the stack is last.
+	apply func(values []float64) []float64
+}
+
+var stackOperations = map[string]stackOperation{
+	// Copies the top value.
+	"dup": {1, func(values []float64) []float64 {
+		return append(values, values[len(values)-1])
+	}},
+	// Removes the top value.
+	"drop": {1, func(values []float64) []float64 {
+		return values[:len(values)-1]
+	}},
+	// Exchanges the top two values.
+	"swap": {2, func(values []float64) []float64 {
+		top := len(values) - 1
+		values[top], values[top-1] = values[top-1], values[top]
+		return values
+	}},
+	// Rotates the stack, moving the top value to the bottom.
+	"roll": {1, func(values []float64) []float64 {
+		return append([]float64{values[len(values)-1]}, values[:len(values)-1]...)
+	}},
+	// Changes the sign of the top value.
+	"neg": {1, func(values []float64) []float64 {
+		values[len(values)-1] = -values[len(values)-1]
+		return values
+	}},
+	// Removes all the values.
+	"clear": {0, func(values []float64) []float64 {
+		return []float64{}
+	}},
+}
+
+// Reports whether the name is an RPN stack operation, such as "swap".
+func IsStackOperation(name string) bool {
+	_, found := stackOperations[name]
+	return found
+}
+
+// Gets the names of the RPN stack operations, sorted.
+func GetStackOperations() []string {
+	names := []string{}
+	for name := range stackOperations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Stack of values for reverse Polish notation (RPN) input, e.g. "3 4 + 2 *".  Numbers, literals and variables are
+// pushed on to the stack, and operators and functions replace the values they use with their result.  The operators,
+// variables and settings are those of the evaluator.
+type RPNStack struct {
+	evaluator *Evaluator
+	values    []float64
+}
+
+func NewRPNStack(evaluator *Evaluator) *RPNStack {
+	stack := RPNStack{}
+	stack.evaluator = evaluator
+	stack.values = []float64{}
+	return &stack
+}
+
+// Gets the values on the stack, with the top of the stack last.
+func (stack *RPNStack) GetValues() []float64 {
+	return stack.values
+}
+
+// Evaluates the RPN input.  The stack is only changed when all of the input is valid, in which case the top of the
+// stack becomes $ans and is added to the history.  A minus straight before a number, as in "-5", is a negative number,
+// and "$x =" assigns the top of the stack to $x.
+func (stack *RPNStack) Evaluate(input string) error {
+	return stack.EvaluateContext(context.Background(), input)
+}
+
+// Evaluates the RPN input, stopping with the context's error if the context is done before the evaluation ends.  The
+// evaluation is also stopped by the evaluator's limits.  As for Evaluator.EvaluateContext, a registered function cannot
+// be cancelled while it runs.
+func (stack *RPNStack) EvaluateContext(ctx context.Context, input string) error {
+	endEvaluation, err := stack.evaluator.startEvaluation(ctx, input)
+	if err != nil {
+		return err
+	}
+	defer endEvaluation()
+
+	values, err := stack.evaluate(input)
+
+	var evaluationError *EvaluationError
+	if errors.As(err, &evaluationError) {
+		evaluationError.setColumn(input)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if len(values) > 0 {
+		top := values[len(values)-1]
+		if err := stack.evaluator.Variables.SetVariable(ansVariable, top); err != nil {
+			return err
+		}
+		stack.evaluator.addHistoryEntry(input, top)
+	}
+
+	stack.values = values
+	return nil
+}
+
+func (stack *RPNStack) evaluate(input string) ([]float64, error) {
+	evaluator := stack.evaluator
+	values := append([]float64{}, stack.values...)
+
+	lexAn := CreateLexicalAnalyser(input)
+	token := lexAn.ParseNextToken()
+
+	for token != TokenEnd {
+		if err := evaluator.iterate(lexAn); err != nil {
+			return nil, err
+		}
+
+		operationError := newEvaluationError(nil, lexAn)
+		if len(values) < getOperands(lexAn) {
+			operationError.Err = ErrStackUnderflow
+			return nil, operationError
+		}
+
+		switch token {
+		case TokenNumber:
+			if err := evaluator.checkNumber(lexAn); err != nil {
+				return nil, err
+			}
+			values = append(values, lexAn.GetNumericValue())
+
+		case TokenOpMinus:
+			// A minus joined to the following number negates it, otherwise it is a subtraction.
+			_, minusEnd := lexAn.GetTokenSpan()
+			if lexAn.ParseNextToken() == TokenNumber {
+				if numberStart, _ := lexAn.GetTokenSpan(); numberStart == minusEnd {
+					if err := evaluator.checkNumber(lexAn); err != nil {
+						return nil, err
+					}
+					values = append(values, -lexAn.GetNumericValue())
+					break
+				}
+			}
+
+			if len(values) < 2 {
+				operationError.Err = ErrStackUnderflow
+				return nil, operationError
+			}
+
+			result, err := evaluator.applyOperator(TokenOpMinus, values[len(values)-2], values[len(values)-1],
+				operationError)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values[:len(values)-2], result)
+
+			// The token after the minus has already been read.
+			token = lexAn.GetCurrentToken()
+			continue
+
+		case TokenOpPlus, TokenOpMultiply, TokenOpDivide, TokenOpPower:
+			result, err := evaluator.applyOperator(token, values[len(values)-2], values[len(values)-1], operationError)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values[:len(values)-2], result)
+
+		case TokenPercent:
+			values[len(values)-1] /= 100.0
+
+		case TokenVariable:
+			variableName := lexAn.GetTextValue()
+			if lexAn.ParseNextToken() == TokenOpAssign {
+				if len(values) == 0 {
+					operationError.Err = ErrStackUnderflow
+					return nil, operationError
+				}
+
+				if _, isHistory := parseHistoryVariable(variableName); isHistory {
+					return nil, newEvaluationError(ErrHistoryReadOnly, lexAn)
+				}
+
+				if evaluator.IsConstant(variableName) {
+					operationError.Err = ErrConstantAssignment
+					operationError.Name = variableName
+					return nil, operationError
+				}
+
+				if err := evaluator.Variables.SetVariable(variableName, values[len(values)-1]); err != nil {
+					operationError.Err = err
+					operationError.Name = variableName
+					return nil, operationError
+				}
+				break
+			}
+
+			value, err := evaluator.getVariableValue(variableName, operationError)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+
+			token = lexAn.GetCurrentToken()
+			continue
+
+		case TokenIdentifier:
+			name := lexAn.GetTextValue()
+			if value, found := specialValueLiterals[name]; found {
+				values = append(values, value)
+			} else if operation, found := stackOperations[name]; found {
+				values = operation.apply(values)
+			} else if registered, found := evaluator.functions[name]; found {
+				// A function uses as many values as it has arguments, or all of them if it is variadic.
+				operands := registered.arity
+				if operands == VariadicArity {
+					operands = len(values)
+				}
+
+				if len(values) < operands {
+					operationError.Err = ErrStackUnderflow
+					return nil, operationError
+				}
+
+				arguments := append([]float64{}, values[len(values)-operands:]...)
+				result, err := evaluator.callFunction(name, registered, arguments, operationError)
+				if err != nil {
+					return nil, err
+				}
+				values = append(values[:len(values)-operands], result)
+			} else {
+				operationError.Err = ErrUnknownOperation
+				operationError.Name = name
+				return nil, operationError
+			}
+
+		default:
+			return nil, newEvaluationError(ErrSyntax, lexAn)
+		}
+
+		token = lexAn.ParseNextToken()
+	}
+
+	return values, nil
+}
+
+// Gets the number of values on the stack that the current token uses.
+func getOperands(lexAn LexicalAnalyser) int {
+	switch lexAn.GetCurrentToken() {
+	case TokenOpPlus, TokenOpMultiply, TokenOpDivide, TokenOpPower:
+		return 2
+	case TokenPercent:
+		return 1
+	case TokenIdentifier:
+		return stackOperations[lexAn.GetTextValue()].operands
+	default:
+		return 0
+	}
+}
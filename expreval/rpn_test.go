@@ -0,0 +1,94 @@
+package expreval
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestRPNStackOperators(t *testing.T) {
+	assertRPNStack(t, "3 4 + 2 *", []float64{14})
+	assertRPNStack(t, "10 4 - 2 / 3 ^", []float64{27})
+	assertRPNStack(t, "200 15 % *", []float64{30})
+	assertRPNStack(t, "1 -5 - 3 -2", []float64{6, 3, -2})
+	assertRPNStack(t, "inf nan", []float64{math.Inf(1), math.NaN()})
+}
+
+func TestRPNStackOperations(t *testing.T) {
+	assertRPNStack(t, "1 2 dup", []float64{1, 2, 2})
+	assertRPNStack(t, "1 2 drop", []float64{1})
+	assertRPNStack(t, "1 2 swap", []float64{2, 1})
+	assertRPNStack(t, "1 2 3 roll", []float64{3, 1, 2})
+	assertRPNStack(t, "1 2 neg", []float64{1, -2})
+	assertRPNStack(t, "1 2 clear", []float64{})
+}
+
+func TestRPNStackVariables(t *testing.T) {
+	evaluator := NewEvaluator()
+	stack := NewRPNStack(evaluator)
+
+	stack.Evaluate("6 $x = 2 *")
+	assertVariableValue(t, evaluator, "$x", 6)
+	assertVariableValue(t, evaluator, "$ans", 12)
+
+	stack.Evaluate("$x $_1 +")
+	if !reflect.DeepEqual(stack.GetValues(), []float64{12, 18}) {
+		t.Error("Expected:", []float64{12, 18}, "Actual:", stack.GetValues())
+	}
+
+	if len(evaluator.History) != 2 || evaluator.History[1].Expression != "$x $_1 +" {
+		t.Error("Expected:", "2 history entries", "Actual:", evaluator.History)
+	}
+}
+
+func TestRPNStackErrors(t *testing.T) {
+	evaluator := NewEvaluator()
+	stack := NewRPNStack(evaluator)
+	stack.Evaluate("1")
+
+	// Errors leave the stack unchanged.
+	assertRPNError(t, stack, "2 + +", ErrStackUnderflow, 5)
+	assertRPNError(t, stack, "0 /", ErrDivideByZero, 3)
+	assertRPNError(t, stack, "2 foo", ErrUnknownOperation, 3)
+	assertRPNError(t, stack, "$undefined", ErrUndefinedVariable, 1)
+	assertRPNError(t, stack, "( 1", ErrSyntax, 1)
+	assertRPNError(t, stack, "clear swap", ErrStackUnderflow, 7)
+
+	evaluator.NonFinitePolicy = NonFiniteError
+	assertRPNError(t, stack, "10 400 ^ 10 400 ^ *", ErrArithmeticOverflow, 8)
+
+	if !reflect.DeepEqual(stack.GetValues(), []float64{1}) {
+		t.Error("Expected:", []float64{1}, "Actual:", stack.GetValues())
+	}
+}
+
+func assertRPNStack(t *testing.T, input string, expectedValues []float64) {
+	stack := NewRPNStack(NewEvaluator())
+	stack.evaluator.NonFinitePolicy = NonFinitePropagate
+
+	err := stack.Evaluate(input)
+	if err != nil {
+		t.Error("Input:", input, "Expected:", nil, "Actual:", err)
+	}
+
+	values := stack.GetValues()
+	matches := len(values) == len(expectedValues)
+	for index := 0; matches && index < len(values); index++ {
+		matches = values[index] == expectedValues[index] ||
+			(math.IsNaN(values[index]) && math.IsNaN(expectedValues[index]))
+	}
+
+	if !matches {
+		t.Error("Input:", input, "Expected:", expectedValues, "Actual:", values)
+	}
+}
+
+func assertRPNError(t *testing.T, stack *RPNStack, input string, expectedError error, expectedColumn int) {
+	err := stack.Evaluate(input)
+
+	var evaluationError *EvaluationError
+	if !errors.As(err, &evaluationError) || !errors.Is(err, expectedError) || evaluationError.Column != expectedColumn {
+		t.Error("Input:", input, "Expected:", expectedError, "at column", expectedColumn, "Actual:", err)
+	}
+}
@@ -0,0 +1,79 @@
+package expreval
+
+import (
+	"sort"
+)
+
+const (
+	// Maximum number of names suggested for a misspelt name.
+	MaxSuggestions = 3
+	// Maximum number of single character edits between a misspelt name and a suggestion.
+	MaxSuggestionDistance = 2
+)
+
+// Finds the names closest to the supplied name, nearest first.
+func suggestNames(name string, names []string) []string {
+	distances := make(map[string]int)
+	suggestions := []string{}
+
+	for _, candidate := range names {
+		distance := editDistance(name, candidate)
+		if distance <= MaxSuggestionDistance {
+			distances[candidate] = distance
+			suggestions = append(suggestions, candidate)
+		}
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if distances[suggestions[i]] != distances[suggestions[j]] {
+			return distances[suggestions[i]] < distances[suggestions[j]]
+		}
+		return suggestions[i] < suggestions[j]
+	})
+
+	if len(suggestions) > MaxSuggestions {
+		suggestions = suggestions[:MaxSuggestions]
+	}
+
+	return suggestions
+}
+
+// Calculates the Levenshtein distance between two strings.
+func editDistance(a string, b string) int {
+	runesA, runesB := []rune(a), []rune(b)
+	previous := make([]int, len(runesB)+1)
+	current := make([]int, len(runesB)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(runesA); i++ {
+		current[0] = i
+		for j := 1; j <= len(runesB); j++ {
+			substitutionCost := 1
+			if runesA[i-1] == runesB[j-1] {
+				substitutionCost = 0
+			}
+
+			current[j] = minInt(minInt(previous[j]+1, current[j-1]+1), previous[j-1]+substitutionCost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(runesB)]
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,110 @@
+package expreval
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrReadOnlyVariable = errors.New("variable is read only")
+
+// Source of the values of variables, such as "$rate", used by the evaluator.  A program embedding the evaluator can
+// implement it to supply the values from its own data.  The variables of an evaluator are read by the contexts created
+// from it, so a resolver used with contexts must be safe to read from several goroutines.
+type VariableResolver interface {
+	// Gets the value of a variable, and whether it is defined.
+	GetVariable(name string) (float64, bool)
+	// Sets the value of a variable.  Returns ErrReadOnlyVariable if the variable cannot be set.
+	SetVariable(name string, value float64) error
+	// Removes a variable.  Returns ErrUndefinedVariable if it is not defined, or ErrReadOnlyVariable if it cannot be
+	// removed.
+	DeleteVariable(name string) error
+	// Gets the names of the defined variables, in any order.
+	GetVariableNames() []string
+}
+
+// Variables held in a map, the default for the evaluator.
+type VariableMap map[string]float64
+
+func NewVariableMap() VariableMap {
+	return make(VariableMap)
+}
+
+func (variableMap VariableMap) GetVariable(name string) (float64, bool) {
+	value, found := variableMap[name]
+	return value, found
+}
+
+func (variableMap VariableMap) SetVariable(name string, value float64) error {
+	variableMap[name] = value
+	return nil
+}
+
+func (variableMap VariableMap) DeleteVariable(name string) error {
+	if _, found := variableMap[name]; !found {
+		return fmt.Errorf("%w: %s", ErrUndefinedVariable, name)
+	}
+
+	delete(variableMap, name)
+	return nil
+}
+
+func (variableMap VariableMap) GetVariableNames() []string {
+	names := []string{}
+	for name := range variableMap {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Variables looked up in a list of resolvers in turn, e.g. the user's assignments over values supplied by the program
+// embedding the evaluator.  Variables are set in and removed from the first resolver only, so the other resolvers are
+// read only.  A variable set in the first resolver hides any variable with the same name in the others.
+type LayeredVariables struct {
+	layers []VariableResolver
+}
+
+// Creates layered variables from the writable resolver and the read only resolvers under it, searched in order.
+func NewLayeredVariables(writable VariableResolver, readOnly ...VariableResolver) *LayeredVariables {
+	layeredVariables := LayeredVariables{}
+	layeredVariables.layers = append([]VariableResolver{writable}, readOnly...)
+	return &layeredVariables
+}
+
+func (layeredVariables *LayeredVariables) GetVariable(name string) (float64, bool) {
+	for _, layer := range layeredVariables.layers {
+		if value, found := layer.GetVariable(name); found {
+			return value, true
+		}
+	}
+	return 0.0, false
+}
+
+func (layeredVariables *LayeredVariables) SetVariable(name string, value float64) error {
+	return layeredVariables.layers[0].SetVariable(name, value)
+}
+
+func (layeredVariables *LayeredVariables) DeleteVariable(name string) error {
+	if _, found := layeredVariables.layers[0].GetVariable(name); found {
+		return layeredVariables.layers[0].DeleteVariable(name)
+	}
+
+	if _, found := layeredVariables.GetVariable(name); found {
+		return fmt.Errorf("%w: %s", ErrReadOnlyVariable, name)
+	}
+
+	return fmt.Errorf("%w: %s", ErrUndefinedVariable, name)
+}
+
+func (layeredVariables *LayeredVariables) GetVariableNames() []string {
+	found := make(map[string]bool)
+	names := []string{}
+	for _, layer := range layeredVariables.layers {
+		for _, name := range layer.GetVariableNames() {
+			if !found[name] {
+				found[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
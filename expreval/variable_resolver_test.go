@@ -0,0 +1,133 @@
+package expreval
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// Read only variables supplied from the fields of a struct when they are used, as a program embedding the evaluator
+// might.
+type accountVariables struct {
+	balance float64
+	rate    float64
+}
+
+func (account *accountVariables) GetVariable(name string) (float64, bool) {
+	switch name {
+	case "$balance":
+		return account.balance, true
+	case "$rate":
+		return account.rate, true
+	default:
+		return 0.0, false
+	}
+}
+
+func (account *accountVariables) SetVariable(name string, value float64) error {
+	return ErrReadOnlyVariable
+}
+
+func (account *accountVariables) DeleteVariable(name string) error {
+	return ErrReadOnlyVariable
+}
+
+func (account *accountVariables) GetVariableNames() []string {
+	return []string{"$balance", "$rate"}
+}
+
+func TestVariableMap(t *testing.T) {
+	variableMap := NewVariableMap()
+	variableMap.SetVariable("$a", 1)
+
+	value, found := variableMap.GetVariable("$a")
+	if value != 1 || !found {
+		t.Error("Expected:", 1, "Actual:", value, found)
+	}
+
+	if !reflect.DeepEqual(variableMap.GetVariableNames(), []string{"$a"}) {
+		t.Error("Expected:", []string{"$a"}, "Actual:", variableMap.GetVariableNames())
+	}
+
+	if err := variableMap.DeleteVariable("$a"); err != nil {
+		t.Error("Expected:", nil, "Actual:", err)
+	}
+
+	if err := variableMap.DeleteVariable("$a"); !errors.Is(err, ErrUndefinedVariable) {
+		t.Error("Expected:", ErrUndefinedVariable, "Actual:", err)
+	}
+}
+
+func TestEvaluateLayeredVariables(t *testing.T) {
+	account := &accountVariables{100, 0.05}
+	userVariables := NewVariableMap()
+	evaluator := NewEvaluator()
+	evaluator.Variables = NewLayeredVariables(userVariables, account)
+
+	// Values are read from the account when they are used.
+	result, err := evaluator.Evaluate("$interest = $balance * $rate")
+	assertEvaluatedResult(t, 5, nil, result, err)
+	account.balance = 200
+	result, err = evaluator.Evaluate("$balance * $rate")
+	assertEvaluatedResult(t, 10, nil, result, err)
+
+	// An assignment hides the account value until it is removed.
+	result, err = evaluator.Evaluate("$rate = 0.1")
+	assertEvaluatedResult(t, 0.1, nil, result, err)
+	result, err = evaluator.Evaluate("$balance * $rate")
+	assertEvaluatedResult(t, 20, nil, result, err)
+	if account.rate != 0.05 {
+		t.Error("Expected:", 0.05, "Actual:", account.rate)
+	}
+
+	if err := evaluator.UnsetVariable("$rate"); err != nil {
+		t.Error("Expected:", nil, "Actual:", err)
+	}
+	assertVariableValue(t, evaluator, "$rate", 0.05)
+
+	if err := evaluator.UnsetVariable("$rate"); !errors.Is(err, ErrReadOnlyVariable) {
+		t.Error("Expected:", ErrReadOnlyVariable, "Actual:", err)
+	}
+
+	expectedNames := []string{"$ans", "$balance", "$interest", "$rate"}
+	if !reflect.DeepEqual(evaluator.GetVariableNames(), expectedNames) {
+		t.Error("Expected:", expectedNames, "Actual:", evaluator.GetVariableNames())
+	}
+
+	evaluator.ClearVariables()
+	names := userVariables.GetVariableNames()
+	sort.Strings(names)
+	expectedNames = []string{"$ans", "$balance", "$rate"}
+	if !reflect.DeepEqual(names, []string{"$ans"}) || !reflect.DeepEqual(evaluator.GetVariableNames(), expectedNames) {
+		t.Error("Expected:", expectedNames, "Actual:", evaluator.GetVariableNames())
+	}
+}
+
+func TestEvaluateReadOnlyVariables(t *testing.T) {
+	evaluator := NewEvaluator()
+	evaluator.Variables = &accountVariables{100, 0.05}
+
+	// Without a writable layer neither the assignment nor $ans can be set.
+	_, err := evaluator.Evaluate("$x = $rate = 1")
+	var evaluationError *EvaluationError
+	if !errors.As(err, &evaluationError) || evaluationError.Err != ErrReadOnlyVariable ||
+		evaluationError.Name != "$rate" || evaluationError.Column != 6 {
+		t.Error("Expected:", ErrReadOnlyVariable, "$rate at column 6", "Actual:", err)
+	}
+
+	_, err = evaluator.Evaluate("$balance")
+	if !errors.Is(err, ErrReadOnlyVariable) || len(evaluator.History) != 0 {
+		t.Error("Expected:", ErrReadOnlyVariable, "Actual:", err)
+	}
+
+	err = NewRPNStack(evaluator).Evaluate("1 $rate =")
+	if !errors.Is(err, ErrReadOnlyVariable) {
+		t.Error("Expected:", ErrReadOnlyVariable, "Actual:", err)
+	}
+
+	err = evaluator.RenameVariable("$rate", "$r")
+	if !errors.Is(err, ErrReadOnlyVariable) {
+		t.Error("Expected:", ErrReadOnlyVariable, "Actual:", err)
+	}
+}
@@ -0,0 +1,124 @@
+package expreval
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var ErrConstantAssignment = errors.New("cannot assign to constant")
+var ErrInvalidVariable = errors.New("invalid variable")
+
+// Reports whether the name can be assigned as a variable, i.e. it is a variable name other than $ans or a history
+// variable such as "$_3".
+func IsAssignableVariable(name string) bool {
+	lexAn := CreateLexicalAnalyser(name)
+	if lexAn.ParseNextToken() != TokenVariable || lexAn.GetTextValue() != name || name == ansVariable {
+		return false
+	}
+
+	_, isHistory := parseHistoryVariable(name)
+	return !isHistory
+}
+
+// Gets the names of the variables, sorted.
+func (evaluator *Evaluator) GetVariableNames() []string {
+	names := append([]string{}, evaluator.Variables.GetVariableNames()...)
+	sort.Strings(names)
+	return names
+}
+
+// Reports whether the variable is a constant.
+func (evaluator *Evaluator) IsConstant(name string) bool {
+	return evaluator.Constants[name]
+}
+
+// Sets a constant, a variable that cannot be assigned again.
+func (evaluator *Evaluator) SetConstant(name string, value float64) error {
+	if !IsAssignableVariable(name) {
+		return fmt.Errorf("%w: %s", ErrInvalidVariable, name)
+	}
+
+	if evaluator.IsConstant(name) {
+		return fmt.Errorf("%w: %s", ErrConstantAssignment, name)
+	}
+
+	if err := evaluator.Variables.SetVariable(name, value); err != nil {
+		return err
+	}
+
+	evaluator.MarkConstant(name, true)
+	return nil
+}
+
+// Removes a variable or constant.
+func (evaluator *Evaluator) UnsetVariable(name string) error {
+	if err := evaluator.Variables.DeleteVariable(name); err != nil {
+		return err
+	}
+
+	if evaluator.IsConstant(name) {
+		evaluator.MarkConstant(name, false)
+	}
+	return nil
+}
+
+// Removes the variables, keeping $ans, the constants and any variables that are read only.
+func (evaluator *Evaluator) ClearVariables() {
+	for _, name := range evaluator.Variables.GetVariableNames() {
+		if name != ansVariable && !evaluator.IsConstant(name) {
+			evaluator.Variables.DeleteVariable(name)
+		}
+	}
+}
+
+// Renames a variable or constant, replacing any variable with the new name.
+func (evaluator *Evaluator) RenameVariable(oldName string, newName string) error {
+	value, found := evaluator.Variables.GetVariable(oldName)
+	if !found {
+		return fmt.Errorf("%w: %s", ErrUndefinedVariable, oldName)
+	}
+
+	if oldName == newName {
+		return nil
+	}
+
+	if !IsAssignableVariable(newName) {
+		return fmt.Errorf("%w: %s", ErrInvalidVariable, newName)
+	}
+
+	if evaluator.IsConstant(newName) {
+		return fmt.Errorf("%w: %s", ErrConstantAssignment, newName)
+	}
+
+	if err := evaluator.Variables.DeleteVariable(oldName); err != nil {
+		return err
+	}
+
+	if err := evaluator.Variables.SetVariable(newName, value); err != nil {
+		evaluator.Variables.SetVariable(oldName, value)
+		return err
+	}
+
+	if evaluator.IsConstant(oldName) {
+		evaluator.MarkConstant(newName, true)
+		evaluator.MarkConstant(oldName, false)
+	}
+	return nil
+}
+
+// Marks a variable as a constant or as no longer a constant, without changing its value.  The constants are copied, as
+// the map may be shared with contexts evaluating in other goroutines.
+func (evaluator *Evaluator) MarkConstant(name string, isConstant bool) {
+	constants := make(map[string]bool)
+	for constantName, constant := range evaluator.Constants {
+		constants[constantName] = constant
+	}
+
+	if isConstant {
+		constants[name] = true
+	} else {
+		delete(constants, name)
+	}
+	evaluator.Constants = constants
+}
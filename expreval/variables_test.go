@@ -0,0 +1,78 @@
+package expreval
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEvaluateConstantAssignment(t *testing.T) {
+	evaluator := NewEvaluator()
+	evaluator.SetConstant("$g", 9.80665)
+
+	_, err := evaluator.Evaluate("1 + ($g = 10)")
+	var evaluationError *EvaluationError
+	if !errors.As(err, &evaluationError) || evaluationError.Err != ErrConstantAssignment ||
+		evaluationError.Column != 6 {
+		t.Error("Expected:", ErrConstantAssignment, "at column 6", "Actual:", err)
+	}
+
+	assertVariableValue(t, evaluator, "$g", 9.80665)
+
+	err = NewRPNStack(evaluator).Evaluate("10 $g =")
+	if !errors.Is(err, ErrConstantAssignment) {
+		t.Error("Expected:", ErrConstantAssignment, "Actual:", err)
+	}
+
+	err = evaluator.SetConstant("$g", 10)
+	if !errors.Is(err, ErrConstantAssignment) {
+		t.Error("Expected:", ErrConstantAssignment, "Actual:", err)
+	}
+
+	err = evaluator.SetConstant("$_1", 10)
+	if !errors.Is(err, ErrInvalidVariable) {
+		t.Error("Expected:", ErrInvalidVariable, "Actual:", err)
+	}
+}
+
+func TestMarkConstant(t *testing.T) {
+	evaluator := NewEvaluator()
+	evaluator.Variables.SetVariable("$x", 1)
+	constants := evaluator.Constants
+
+	// The constants are copied, so a map shared before the change is left as it was.
+	evaluator.MarkConstant("$x", true)
+	if !evaluator.IsConstant("$x") || constants["$x"] {
+		t.Error("Expected:", "$x constant", "Actual:", evaluator.Constants, constants)
+	}
+
+	_, err := evaluator.Evaluate("$x = 2")
+	if !errors.Is(err, ErrConstantAssignment) {
+		t.Error("Expected:", ErrConstantAssignment, "Actual:", err)
+	}
+
+	evaluator.MarkConstant("$x", false)
+	if result, err := evaluator.Evaluate("$x = 2"); err != nil || result != 2 {
+		t.Error("Expected:", 2, "Actual:", result, err)
+	}
+}
+
+func TestRenameVariable(t *testing.T) {
+	evaluator := NewEvaluator()
+	evaluator.SetConstant("$g", 9.80665)
+	evaluator.Variables.SetVariable("$x", 1)
+
+	err := evaluator.RenameVariable("$x", "$g")
+	if !errors.Is(err, ErrConstantAssignment) {
+		t.Error("Expected:", ErrConstantAssignment, "Actual:", err)
+	}
+
+	err = evaluator.RenameVariable("$g", "$gravity")
+	if err != nil || !evaluator.IsConstant("$gravity") || evaluator.IsConstant("$g") {
+		t.Error("Expected:", "$gravity constant", "Actual:", err, evaluator.Constants)
+	}
+
+	err = evaluator.RenameVariable("$x", "$ans")
+	if !errors.Is(err, ErrInvalidVariable) {
+		t.Error("Expected:", ErrInvalidVariable, "Actual:", err)
+	}
+}
@@ -2,42 +2,201 @@ package main
 
 import (
 	"alanmitic/gocalc/command"
-	"alanmitic/gocalc/expreval"
-	"alanmitic/gocalc/resultformatter"
-	"bufio"
+	"alanmitic/gocalc/lineeditor"
+	"alanmitic/gocalc/session"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"os/signal"
 )
 
 func main() {
-	evaluator := expreval.NewEvaluator()
-	reader := bufio.NewReader(os.Stdin)
+	os.Exit(run())
+}
 
-	fmt.Println("gocalc version 0.0.3\n\nType 'help' and ENTER for help or 'exit' and ENTER to quit")
+// Runs gocalc and returns the exit status.  Exiting is left to main so that deferred cleanup runs first.
+func run() int {
+	options, err := parseOptions(os.Args[1:])
+	if err != nil {
+		return 2
+	}
 
-	resultformatter := resultformatter.NewResultFormatter()
-	commandParser := command.NewCommandParser(evaluator, resultformatter)
+	rcFile := getRCFile(options)
+	session := session.NewSession(os.Stdin, os.Stdout)
 
-	for {
-		fmt.Print("gocalc >> ")
-		exprOrCmd, _ := reader.ReadString('\n')
+	// When not interactive only the results are written to stdout, and any error gives a non-zero exit status.
+	session.SetErrorOutput(os.Stderr)
 
-		cmd, arguments, err := commandParser.ParseCommand(exprOrCmd)
-		if err != nil {
-			fmt.Println("COMMAND ERROR:", err)
-			// TODO: Output command usage.
-			continue
+	// The rc file presets the session, followed by the saved state and then the options, so that each takes precedence
+	// over the last.
+	if rcFile != "" {
+		err := session.RunStartupFile(rcFile)
+		if errors.Is(err, command.ErrExit) {
+			return exitCode(err)
+		}
+
+		// Errors in the file have already been reported with their line numbers.
+		if err != nil && !errors.Is(err, command.ErrScriptFailed) {
+			fmt.Fprintln(os.Stderr, "ERROR:", err)
 		}
+	}
+
+	if options.autosave {
+		defer autosave(session)()
+	}
+
+	err = options.apply(session.GetEvaluator(), session.GetResultFormatter())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 2
+	}
+
+	switch {
+	case len(options.expressions) > 0:
+		// One-shot mode, e.g. "gocalc -e '2^10'".
+		status := 0
+		for _, expression := range options.expressions {
+			err := session.ExecuteLine(expression)
+			if errors.Is(err, command.ErrExit) {
+				return exitCode(err)
+			}
 
-		if cmd != nil {
-			cmd.Execute(arguments)
-		} else {
-			result, err := evaluator.Evaluate(exprOrCmd)
 			if err != nil {
-				fmt.Println("ERROR:", err)
-			} else {
-				fmt.Println(resultformatter.FormatValue(result))
+				session.PrintError(err, expression)
+				status = 1
 			}
 		}
+		return status
+
+	case options.scriptFile != "":
+		// Run a script file, e.g. "gocalc file.gc" or a script starting with "#!/usr/bin/env gocalc".
+		return scriptExitStatus(session.RunFile(options.scriptFile))
+
+	case !isTerminal(os.Stdin):
+		// Pipe mode, e.g. "echo '1+2' | gocalc".
+		return scriptExitStatus(session.RunScript("<stdin>"))
+
+	default:
+		session.SetErrorOutput(os.Stdout)
+
+		// Edit lines in the terminal, with the history kept between sessions.
+		if isTerminal(os.Stdout) {
+			history := lineeditor.NewHistory(lineeditor.MaxHistory)
+			historyFile, err := lineeditor.DefaultHistoryFile()
+			if err == nil {
+				history.Load(historyFile)
+				defer history.Save(historyFile)
+			}
+
+			lineEditor := lineeditor.NewLineEditor(os.Stdin, os.Stdout, history)
+			lineEditor.SetCompleter(command.NewCompleter(session.GetCommandParser(), session.GetEvaluator()))
+			session.SetLineReader(lineEditor)
+		}
+
+		return runInteractive(session)
 	}
 }
+
+// Gets the rc file to run at startup: the file given by --rc, or otherwise the default rc file if it exists.  Returns
+// an empty name if there is no rc file to run.
+func getRCFile(options *options) string {
+	if options.norc {
+		return ""
+	}
+
+	if options.rcFile != "" {
+		return options.rcFile
+	}
+
+	rcFile, err := session.DefaultRCFile()
+	if err != nil {
+		return ""
+	}
+
+	if _, err := os.Stat(rcFile); err != nil {
+		return ""
+	}
+
+	return rcFile
+}
+
+// Loads the default state file, and returns a function that saves the state to it again.  A state file that cannot be
+// loaded is not overwritten.
+func autosave(session *session.Session) func() {
+	stateFile, err := command.DefaultStateFile()
+	if err == nil {
+		err = command.LoadStateFile(stateFile, session.GetCommandParser())
+		if errors.Is(err, fs.ErrNotExist) {
+			err = nil
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return func() {}
+	}
+
+	return func() {
+		err := command.SaveStateFile(stateFile, session.GetCommandParser())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "ERROR:", err)
+		}
+	}
+}
+
+// Runs the prompt with an interrupt (Ctrl-C) discarding the current input, or stopping a running script, rather than
+// ending the process.  While a line is edited the terminal is in raw mode, so Ctrl-C is read by the line editor
+// instead.
+func runInteractive(session *session.Session) int {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	defer signal.Stop(signals)
+
+	go func() {
+		for range signals {
+			session.Interrupt()
+		}
+	}()
+
+	err := session.RunPrompt()
+	if errors.Is(err, command.ErrExit) {
+		return exitCode(err)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 1
+	}
+
+	return 0
+}
+
+func scriptExitStatus(err error) int {
+	if errors.Is(err, command.ErrExit) {
+		return exitCode(err)
+	}
+
+	if err != nil {
+		if err != command.ErrScriptFailed {
+			fmt.Fprintln(os.Stderr, "ERROR:", err)
+		}
+		return 1
+	}
+
+	return 0
+}
+
+func exitCode(err error) int {
+	var exitError *command.ExitError
+	if errors.As(err, &exitError) {
+		return exitError.Code
+	}
+	return 0
+}
+
+// Reports whether the file is a terminal rather than a pipe or a regular file.
+func isTerminal(file *os.File) bool {
+	fileInfo, err := file.Stat()
+	return err == nil && fileInfo.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,87 @@
+package lineeditor
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Completes the word before the cursor.  The text of the line up to the cursor is passed, and the index of the start of
+// the word in the text is returned along with the candidates that could replace the word.
+type Completer interface {
+	Complete(text string) (int, []string)
+}
+
+// Sets the completer used when Tab is pressed.  Without a completer Tab is ignored.
+func (lineEditor *LineEditor) SetCompleter(completer Completer) {
+	lineEditor.completer = completer
+}
+
+// Completes the word before the cursor.  A single candidate replaces the word, followed by a space at the end of the
+// line.  Otherwise the word is extended to the prefix common to the candidates, or the candidates are listed below the
+// line when it cannot be extended.
+func (lineEditor *LineEditor) complete() {
+	if lineEditor.completer == nil {
+		return
+	}
+
+	text := string(lineEditor.buffer[:lineEditor.cursor])
+	start, candidates := lineEditor.completer.Complete(text)
+	if len(candidates) == 0 || start < 0 || start > len(text) {
+		return
+	}
+
+	word := text[start:]
+	wordStart := lineEditor.cursor - utf8.RuneCountInString(word)
+
+	replacement := findCommonPrefix(candidates)
+	if len(candidates) == 1 && lineEditor.cursor == len(lineEditor.buffer) {
+		replacement += " "
+	}
+
+	if replacement == word && len(candidates) > 1 {
+		lineEditor.listCandidates(candidates)
+		return
+	}
+
+	lineEditor.deleteRange(wordStart, lineEditor.cursor)
+	for _, c := range replacement {
+		lineEditor.insert(c)
+	}
+}
+
+// Lists the candidates in columns below the line.  The line is redrawn after the list.
+func (lineEditor *LineEditor) listCandidates(candidates []string) {
+	columnWidth := 0
+	for _, candidate := range candidates {
+		columnWidth = maxInt(columnWidth, utf8.RuneCountInString(candidate)+2)
+	}
+	columns := maxInt(lineEditor.getWidth()/columnWidth, 1)
+
+	var list strings.Builder
+	list.WriteString("\r\n")
+	for index, candidate := range candidates {
+		list.WriteString(candidate)
+		if (index+1)%columns == 0 || index == len(candidates)-1 {
+			list.WriteString("\r\n")
+		} else {
+			list.WriteString(strings.Repeat(" ", columnWidth-utf8.RuneCountInString(candidate)))
+		}
+	}
+
+	lineEditor.write(list.String())
+}
+
+func findCommonPrefix(candidates []string) string {
+	prefix := []rune(candidates[0])
+	for _, candidate := range candidates[1:] {
+		length := 0
+		for _, c := range candidate {
+			if length == len(prefix) || prefix[length] != c {
+				break
+			}
+			length++
+		}
+		prefix = prefix[:length]
+	}
+	return string(prefix)
+}
@@ -0,0 +1,85 @@
+package lineeditor
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+type testCompleter []string
+
+func (names testCompleter) Complete(text string) (int, []string) {
+	start := strings.LastIndex(text, " ") + 1
+	candidates := []string{}
+	for _, name := range names {
+		if strings.HasPrefix(name, text[start:]) {
+			candidates = append(candidates, name)
+		}
+	}
+	return start, candidates
+}
+
+func TestLineEditorComplete(t *testing.T) {
+	completer := testCompleter{"hex", "help", "$rate", "$ratio"}
+
+	// A single candidate, and the common prefix of several candidates.
+	assertCompletedLine(t, completer, "hel\t\r", "help ")
+	assertCompletedLine(t, completer, "1 + $r\t\r", "1 + $rat")
+	assertCompletedLine(t, completer, "$rati\t * 2\r", "$ratio  * 2")
+	assertCompletedLine(t, completer, "$re\x01\t\r", "$re")
+
+	// Within the line no space is added.
+	assertCompletedLine(t, completer, "$rati * 2\x1b[D\x1b[D\x1b[D\x1b[D\t\r", "$ratio * 2")
+}
+
+func TestLineEditorCompleteListsCandidates(t *testing.T) {
+	output := bytes.Buffer{}
+	lineEditor := NewLineEditor(strings.NewReader("h\t\t\r"), &output, NewHistory(MaxHistory))
+	lineEditor.SetCompleter(testCompleter{"hex", "help", "$rate"})
+
+	line, err := lineEditor.edit("> ")
+	if line != "he" || err != nil {
+		t.Error("Expected:", "he", nil, "Actual:", line, err)
+	}
+
+	if !strings.Contains(output.String(), "\r\nhex   help\r\n") {
+		t.Errorf("Expected candidates listed, Actual: %q", output.String())
+	}
+}
+
+func TestLineEditorWithoutCompleter(t *testing.T) {
+	lineEditor := NewLineEditor(strings.NewReader("he\t\r"), io.Discard, NewHistory(MaxHistory))
+	line, _ := lineEditor.edit("> ")
+	if line != "he" {
+		t.Error("Expected:", "he", "Actual:", line)
+	}
+}
+
+func TestFindCommonPrefix(t *testing.T) {
+	assertCommonPrefix(t, []string{"help"}, "help")
+	assertCommonPrefix(t, []string{"help", "hex"}, "he")
+	assertCommonPrefix(t, []string{"$ratio", "$rate", "$r"}, "$r")
+	assertCommonPrefix(t, []string{"fix", "hex"}, "")
+}
+
+func assertCompletedLine(t *testing.T, completer Completer, keys string, expectedLine string) {
+	lineEditor := NewLineEditor(strings.NewReader(keys), io.Discard, NewHistory(MaxHistory))
+	lineEditor.SetCompleter(completer)
+
+	line, err := lineEditor.edit("> ")
+	if err != nil {
+		t.Error("Expected:", nil, "Actual:", err)
+	}
+
+	if line != expectedLine {
+		t.Errorf("Keys: %q Expected: %q Actual: %q", keys, expectedLine, line)
+	}
+}
+
+func assertCommonPrefix(t *testing.T, candidates []string, expectedPrefix string) {
+	prefix := findCommonPrefix(candidates)
+	if prefix != expectedPrefix {
+		t.Error("Candidates:", candidates, "Expected:", expectedPrefix, "Actual:", prefix)
+	}
+}
@@ -0,0 +1,550 @@
+package lineeditor
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+const (
+	// Width assumed when the width of the terminal cannot be found.
+	DefaultWidth = 80
+)
+
+var ErrInterrupted = errors.New("input interrupted")
+
+// Keys that are not a single character.  Control keys are returned as their control character, e.g. Ctrl-A is 1.
+const (
+	keyUnknown rune = -(iota + 1)
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyHome
+	keyEnd
+	keyDelete
+	keyWordLeft
+	keyWordRight
+	keyDeleteWordLeft
+	keyDeleteWordRight
+)
+
+const (
+	keyCtrlA     rune = 1
+	keyCtrlB     rune = 2
+	keyCtrlC     rune = 3
+	keyCtrlD     rune = 4
+	keyCtrlE     rune = 5
+	keyCtrlF     rune = 6
+	keyCtrlG     rune = 7
+	keyCtrlH     rune = 8
+	keyTab       rune = 9
+	keyLineFeed  rune = 10
+	keyCtrlK     rune = 11
+	keyCtrlL     rune = 12
+	keyEnter     rune = 13
+	keyCtrlN     rune = 14
+	keyCtrlP     rune = 16
+	keyCtrlR     rune = 18
+	keyCtrlU     rune = 21
+	keyCtrlW     rune = 23
+	keyEscape    rune = 27
+	keyBackspace rune = 127
+)
+
+// Pairs of brackets that are highlighted when the cursor is next to one of them.
+var bracketPairs = map[rune]rune{'(': ')', '[': ']'}
+
+// Line editor for a terminal, with cursor movement, word deletion, history, reverse search and completion.  Input that
+// is not a terminal is read a line at a time without editing.
+type LineEditor struct {
+	input     *bufio.Reader
+	output    io.Writer
+	terminal  *os.File
+	history   *History
+	completer Completer
+	prompt    string
+	buffer    []rune
+	cursor    int
+}
+
+// Creates a line editor.  Lines that are entered are added to the history.
+func NewLineEditor(input io.Reader, output io.Writer, history *History) *LineEditor {
+	lineEditor := LineEditor{}
+	lineEditor.input = bufio.NewReader(input)
+	lineEditor.output = output
+	lineEditor.history = history
+
+	if file, ok := input.(*os.File); ok && isTerminal(file.Fd()) {
+		lineEditor.terminal = file
+	}
+
+	return &lineEditor
+}
+
+// Prints the prompt and reads a line, without the line ending.  io.EOF is returned at the end of the input, and
+// ErrInterrupted when the line is abandoned with Ctrl-C.
+func (lineEditor *LineEditor) ReadLine(prompt string) (string, error) {
+	if lineEditor.terminal == nil {
+		return lineEditor.readPlainLine(prompt)
+	}
+
+	restore, err := makeRaw(lineEditor.terminal.Fd())
+	if err != nil {
+		return lineEditor.readPlainLine(prompt)
+	}
+	defer restore()
+
+	return lineEditor.edit(prompt)
+}
+
+func (lineEditor *LineEditor) readPlainLine(prompt string) (string, error) {
+	fmt.Fprint(lineEditor.output, prompt)
+
+	line, err := lineEditor.input.ReadString('\n')
+	line = strings.TrimRight(line, "\r\n")
+	if err == nil {
+		lineEditor.history.Add(line)
+	}
+
+	return line, err
+}
+
+// Edits a line in raw mode until it is entered or abandoned.
+func (lineEditor *LineEditor) edit(prompt string) (string, error) {
+	lineEditor.prompt = prompt
+	lineEditor.buffer = []rune{}
+	lineEditor.cursor = 0
+
+	// The line being edited is kept while moving through the history, so that it can be returned to.
+	historyIndex := len(lineEditor.history.entries)
+	editedLine := []rune{}
+
+	lineEditor.refresh()
+
+	for {
+		key, err := lineEditor.readKey()
+		if err != nil {
+			if err == io.EOF && len(lineEditor.buffer) > 0 {
+				lineEditor.write("\r\n")
+				return string(lineEditor.buffer), err
+			}
+			return "", err
+		}
+
+		if key == keyCtrlR {
+			key, err = lineEditor.reverseSearch()
+			if err != nil {
+				return "", err
+			}
+		}
+
+		switch key {
+		case keyEnter, keyLineFeed:
+			line := string(lineEditor.buffer)
+			lineEditor.cursor = len(lineEditor.buffer)
+			lineEditor.refreshLine(false)
+			lineEditor.write("\r\n")
+			lineEditor.history.Add(line)
+			return line, nil
+
+		case keyCtrlC:
+			lineEditor.write("^C\r\n")
+			return "", ErrInterrupted
+
+		case keyCtrlD:
+			if len(lineEditor.buffer) == 0 {
+				return "", io.EOF
+			}
+			lineEditor.deleteRange(lineEditor.cursor, lineEditor.cursor+1)
+
+		case keyLeft, keyCtrlB:
+			if lineEditor.cursor > 0 {
+				lineEditor.cursor--
+			}
+
+		case keyRight, keyCtrlF:
+			if lineEditor.cursor < len(lineEditor.buffer) {
+				lineEditor.cursor++
+			}
+
+		case keyHome, keyCtrlA:
+			lineEditor.cursor = 0
+
+		case keyEnd, keyCtrlE:
+			lineEditor.cursor = len(lineEditor.buffer)
+
+		case keyWordLeft:
+			lineEditor.cursor = lineEditor.findWordStart()
+
+		case keyWordRight:
+			lineEditor.cursor = lineEditor.findWordEnd()
+
+		case keyBackspace, keyCtrlH:
+			lineEditor.deleteRange(lineEditor.cursor-1, lineEditor.cursor)
+
+		case keyDelete:
+			lineEditor.deleteRange(lineEditor.cursor, lineEditor.cursor+1)
+
+		case keyCtrlW, keyDeleteWordLeft:
+			lineEditor.deleteRange(lineEditor.findWordStart(), lineEditor.cursor)
+
+		case keyDeleteWordRight:
+			lineEditor.deleteRange(lineEditor.cursor, lineEditor.findWordEnd())
+
+		case keyCtrlK:
+			lineEditor.deleteRange(lineEditor.cursor, len(lineEditor.buffer))
+
+		case keyCtrlU:
+			lineEditor.deleteRange(0, lineEditor.cursor)
+
+		case keyUp, keyCtrlP:
+			if historyIndex > 0 {
+				if historyIndex == len(lineEditor.history.entries) {
+					editedLine = lineEditor.buffer
+				}
+				historyIndex--
+				lineEditor.setBuffer([]rune(lineEditor.history.entries[historyIndex]))
+			}
+
+		case keyDown, keyCtrlN:
+			if historyIndex < len(lineEditor.history.entries) {
+				historyIndex++
+				if historyIndex == len(lineEditor.history.entries) {
+					lineEditor.setBuffer(editedLine)
+				} else {
+					lineEditor.setBuffer([]rune(lineEditor.history.entries[historyIndex]))
+				}
+			}
+
+		case keyCtrlL:
+			lineEditor.write("\x1b[H\x1b[2J")
+
+		case keyTab:
+			lineEditor.complete()
+
+		default:
+			if key >= ' ' {
+				lineEditor.insert(key)
+			}
+		}
+
+		lineEditor.refresh()
+	}
+}
+
+// Searches backwards through the history for lines containing the text typed so far.  Ctrl-R finds the next older
+// match, and Ctrl-G cancels the search.  Any other key accepts the match into the line and is returned so that it can
+// be processed, e.g. Enter executes the match.
+func (lineEditor *LineEditor) reverseSearch() (rune, error) {
+	entries := lineEditor.history.entries
+	originalBuffer, originalCursor := lineEditor.buffer, lineEditor.cursor
+	query := []rune{}
+	matchIndex := len(entries)
+	found := true
+
+	search := func(from int) {
+		for index := from; index >= 0; index-- {
+			if strings.Contains(entries[index], string(query)) {
+				matchIndex = index
+				found = true
+				return
+			}
+		}
+		found = false
+	}
+
+	for {
+		status := "reverse-i-search"
+		if !found {
+			status = "failed reverse-i-search"
+		}
+
+		match := ""
+		if matchIndex < len(entries) {
+			match = entries[matchIndex]
+		}
+		lineEditor.write(fmt.Sprintf("\r(%s)`%s': %s\x1b[K", status, string(query), match))
+
+		key, err := lineEditor.readKey()
+		if err != nil {
+			return 0, err
+		}
+
+		switch {
+		case key == keyCtrlR:
+			search(matchIndex - 1)
+
+		case key == keyBackspace || key == keyCtrlH:
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				search(len(entries) - 1)
+			}
+
+		case key == keyCtrlG || key == keyCtrlC:
+			lineEditor.buffer, lineEditor.cursor = originalBuffer, originalCursor
+			return 0, nil
+
+		case key >= ' ':
+			query = append(query, key)
+			search(minInt(matchIndex, len(entries)-1))
+
+		default:
+			if matchIndex < len(entries) {
+				lineEditor.setBuffer([]rune(entries[matchIndex]))
+			}
+			return key, nil
+		}
+	}
+}
+
+// Reads a key, decoding the escape sequences sent for special keys.
+func (lineEditor *LineEditor) readKey() (rune, error) {
+	key, _, err := lineEditor.input.ReadRune()
+	if err != nil || key != keyEscape {
+		return key, err
+	}
+
+	key, _, err = lineEditor.input.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+
+	switch key {
+	case '[':
+		return lineEditor.readControlSequence()
+	case 'O':
+		key, _, err = lineEditor.input.ReadRune()
+		return decodeFinalByte(key, ""), err
+	case 'b':
+		return keyWordLeft, nil
+	case 'f':
+		return keyWordRight, nil
+	case 'd':
+		return keyDeleteWordRight, nil
+	case keyBackspace, keyCtrlH:
+		return keyDeleteWordLeft, nil
+	default:
+		return keyUnknown, nil
+	}
+}
+
+// Reads the parameters and final byte of a control sequence, e.g. "\x1b[1;5C".
+func (lineEditor *LineEditor) readControlSequence() (rune, error) {
+	parameters := ""
+	for {
+		key, _, err := lineEditor.input.ReadRune()
+		if err != nil {
+			return 0, err
+		}
+
+		if key >= 0x40 && key <= 0x7e {
+			return decodeFinalByte(key, parameters), nil
+		}
+		parameters += string(key)
+	}
+}
+
+func decodeFinalByte(finalByte rune, parameters string) rune {
+	// Ctrl or Alt with the left and right arrows moves by word.
+	modified := strings.HasSuffix(parameters, ";5") || strings.HasSuffix(parameters, ";3")
+
+	switch finalByte {
+	case 'A':
+		return keyUp
+	case 'B':
+		return keyDown
+	case 'C':
+		if modified {
+			return keyWordRight
+		}
+		return keyRight
+	case 'D':
+		if modified {
+			return keyWordLeft
+		}
+		return keyLeft
+	case 'H':
+		return keyHome
+	case 'F':
+		return keyEnd
+	case '~':
+		switch parameters {
+		case "1", "7":
+			return keyHome
+		case "4", "8":
+			return keyEnd
+		case "3":
+			return keyDelete
+		}
+	}
+
+	return keyUnknown
+}
+
+func (lineEditor *LineEditor) insert(key rune) {
+	buffer := append([]rune{}, lineEditor.buffer[:lineEditor.cursor]...)
+	buffer = append(buffer, key)
+	lineEditor.buffer = append(buffer, lineEditor.buffer[lineEditor.cursor:]...)
+	lineEditor.cursor++
+}
+
+// Deletes the characters from start up to, but not including, end and moves the cursor to start.
+func (lineEditor *LineEditor) deleteRange(start int, end int) {
+	start = maxInt(start, 0)
+	end = minInt(end, len(lineEditor.buffer))
+	if start >= end {
+		return
+	}
+
+	buffer := append([]rune{}, lineEditor.buffer[:start]...)
+	lineEditor.buffer = append(buffer, lineEditor.buffer[end:]...)
+	lineEditor.cursor = start
+}
+
+func (lineEditor *LineEditor) setBuffer(buffer []rune) {
+	lineEditor.buffer = buffer
+	lineEditor.cursor = len(buffer)
+}
+
+// Finds the start of the word before the cursor.
+func (lineEditor *LineEditor) findWordStart() int {
+	position := lineEditor.cursor
+	for position > 0 && !isWordCharacter(lineEditor.buffer[position-1]) {
+		position--
+	}
+	for position > 0 && isWordCharacter(lineEditor.buffer[position-1]) {
+		position--
+	}
+	return position
+}
+
+// Finds the end of the word after the cursor.
+func (lineEditor *LineEditor) findWordEnd() int {
+	position := lineEditor.cursor
+	for position < len(lineEditor.buffer) && !isWordCharacter(lineEditor.buffer[position]) {
+		position++
+	}
+	for position < len(lineEditor.buffer) && isWordCharacter(lineEditor.buffer[position]) {
+		position++
+	}
+	return position
+}
+
+func isWordCharacter(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c) || c == '$' || c == '.' || c == '_'
+}
+
+// Finds the bracket matching the one at the position, or -1 if there is no bracket or it is unmatched.
+func findMatchingBracket(buffer []rune, position int) int {
+	if position < 0 || position >= len(buffer) {
+		return -1
+	}
+
+	bracket := buffer[position]
+	direction := 1
+	match, isOpening := bracketPairs[bracket]
+	if !isOpening {
+		direction = -1
+		for opening, closing := range bracketPairs {
+			if closing == bracket {
+				match = opening
+			}
+		}
+		if match == 0 {
+			return -1
+		}
+	}
+
+	depth := 0
+	for index := position; index >= 0 && index < len(buffer); index += direction {
+		switch buffer[index] {
+		case bracket:
+			depth++
+		case match:
+			depth--
+			if depth == 0 {
+				return index
+			}
+		}
+	}
+
+	return -1
+}
+
+// Finds the bracket matching one next to the cursor, preferring the bracket under the cursor.
+func (lineEditor *LineEditor) findHighlightedBracket() int {
+	match := findMatchingBracket(lineEditor.buffer, lineEditor.cursor)
+	if match < 0 {
+		match = findMatchingBracket(lineEditor.buffer, lineEditor.cursor-1)
+	}
+	return match
+}
+
+func (lineEditor *LineEditor) refresh() {
+	lineEditor.refreshLine(true)
+}
+
+// Redraws the prompt and line, scrolling the line horizontally when it is wider than the terminal.
+func (lineEditor *LineEditor) refreshLine(highlightBracket bool) {
+	width := lineEditor.getWidth()
+	promptWidth := len([]rune(lineEditor.prompt))
+	available := maxInt(width-promptWidth-1, 1)
+	start := maxInt(lineEditor.cursor-available, 0)
+	end := minInt(len(lineEditor.buffer), start+available)
+
+	highlight := -1
+	if highlightBracket {
+		highlight = lineEditor.findHighlightedBracket()
+	}
+
+	var line strings.Builder
+	line.WriteString("\r" + lineEditor.prompt)
+	for index := start; index < end; index++ {
+		if index == highlight {
+			line.WriteString("\x1b[7m" + string(lineEditor.buffer[index]) + "\x1b[27m")
+		} else {
+			line.WriteRune(lineEditor.buffer[index])
+		}
+	}
+	line.WriteString("\x1b[K\r")
+
+	column := promptWidth + lineEditor.cursor - start
+	if column > 0 {
+		line.WriteString(fmt.Sprintf("\x1b[%dC", column))
+	}
+
+	lineEditor.write(line.String())
+}
+
+// Gets the width of the terminal, or DefaultWidth if it is not known.
+func (lineEditor *LineEditor) getWidth() int {
+	if lineEditor.terminal != nil {
+		if width, err := getWidth(lineEditor.terminal.Fd()); err == nil && width > 0 {
+			return width
+		}
+	}
+	return DefaultWidth
+}
+
+func (lineEditor *LineEditor) write(text string) {
+	io.WriteString(lineEditor.output, text)
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
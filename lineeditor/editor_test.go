@@ -0,0 +1,148 @@
+package lineeditor
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLineEditorInsert(t *testing.T) {
+	assertEditedLine(t, nil, "1+2\r", "1+2")
+	assertEditedLine(t, nil, "$a = 5\n", "$a = 5")
+}
+
+func TestLineEditorCursorMovement(t *testing.T) {
+	// Left arrow, Ctrl-B, Home, End and Ctrl-A.
+	assertEditedLine(t, nil, "12\x1b[D3\r", "132")
+	assertEditedLine(t, nil, "12\x02\x023\r", "312")
+	assertEditedLine(t, nil, "23\x1b[H1\x1b[F4\r", "1234")
+	assertEditedLine(t, nil, "23\x011\r", "123")
+}
+
+func TestLineEditorDelete(t *testing.T) {
+	// Backspace, Delete, Ctrl-K and Ctrl-U.
+	assertEditedLine(t, nil, "123\x7f\r", "12")
+	assertEditedLine(t, nil, "123\x01\x1b[3~\r", "23")
+	assertEditedLine(t, nil, "1234\x1b[D\x1b[D\x0b\r", "12")
+	assertEditedLine(t, nil, "1234\x1b[D\x15\r", "4")
+}
+
+func TestLineEditorWords(t *testing.T) {
+	// Ctrl-W, Alt-Backspace, Alt-B, Ctrl-Left and Alt-D.
+	assertEditedLine(t, nil, "$abc * $def\x17\r", "$abc * ")
+	assertEditedLine(t, nil, "$abc * $def\x1b\x7f\r", "$abc * ")
+	assertEditedLine(t, nil, "$abc * $def\x1bb\x1bbx\r", "x$abc * $def")
+	assertEditedLine(t, nil, "$abc * $def\x1b[1;5Dx\r", "$abc * x$def")
+	assertEditedLine(t, nil, "$abc * $def\x01\x1bd\r", " * $def")
+}
+
+func TestLineEditorHistory(t *testing.T) {
+	history := []string{"1 + 1", "2 + 2"}
+
+	// Up arrow, Ctrl-P and down arrow back to the line being edited.
+	assertEditedLine(t, history, "\x1b[A\r", "2 + 2")
+	assertEditedLine(t, history, "\x10\x10\r", "1 + 1")
+	assertEditedLine(t, history, "3\x1b[A\x1b[A\x1b[B\x1b[B\r", "3")
+}
+
+func TestLineEditorReverseSearch(t *testing.T) {
+	history := []string{"$rate = 5", "$tax = 2", "$rate * 2"}
+
+	assertEditedLine(t, history, "\x12rate\r", "$rate * 2")
+	assertEditedLine(t, history, "\x12rate\x12\r", "$rate = 5")
+	assertEditedLine(t, history, "\x12tax\x1b[D\x1b[D\x7f\r", "$tax  2")
+	assertEditedLine(t, history, "1\x12rate\x07\r", "1")
+}
+
+func TestLineEditorInterruptAndEOF(t *testing.T) {
+	lineEditor := NewLineEditor(strings.NewReader("12\x03"), io.Discard, NewHistory(MaxHistory))
+	_, err := lineEditor.edit("> ")
+	if err != ErrInterrupted {
+		t.Error("Expected:", ErrInterrupted, "Actual:", err)
+	}
+
+	lineEditor = NewLineEditor(strings.NewReader("\x04"), io.Discard, NewHistory(MaxHistory))
+	_, err = lineEditor.edit("> ")
+	if err != io.EOF {
+		t.Error("Expected:", io.EOF, "Actual:", err)
+	}
+}
+
+func TestLineEditorAddsToHistory(t *testing.T) {
+	history := NewHistory(MaxHistory)
+	lineEditor := NewLineEditor(strings.NewReader("1 + 2\r\r1 + 2\r"), io.Discard, history)
+	for index := 0; index < 3; index++ {
+		lineEditor.edit("> ")
+	}
+
+	entries := history.GetEntries()
+	if len(entries) != 1 || entries[0] != "1 + 2" {
+		t.Error("Expected:", []string{"1 + 2"}, "Actual:", entries)
+	}
+}
+
+func TestLineEditorBracketHighlight(t *testing.T) {
+	output := new(bytes.Buffer)
+	lineEditor := NewLineEditor(strings.NewReader("(1 + (2))\r"), output, NewHistory(MaxHistory))
+	lineEditor.edit("> ")
+
+	// Typing the final ')' highlights the first '('.
+	if !strings.Contains(output.String(), "\r> \x1b[7m(\x1b[27m1 + (2))") {
+		t.Error("Expected highlighted bracket, Actual:", output.String())
+	}
+}
+
+func TestFindMatchingBracket(t *testing.T) {
+	buffer := []rune("(1 + (2)) * [3]")
+	assertMatchingBracket(t, buffer, 0, 8)
+	assertMatchingBracket(t, buffer, 8, 0)
+	assertMatchingBracket(t, buffer, 5, 7)
+	assertMatchingBracket(t, buffer, 12, 14)
+	assertMatchingBracket(t, buffer, 1, -1)
+	assertMatchingBracket(t, []rune("(1 + 2"), 0, -1)
+}
+
+func TestLineEditorPlainInput(t *testing.T) {
+	output := new(bytes.Buffer)
+	lineEditor := NewLineEditor(strings.NewReader("1 + 2\r\n3"), output, NewHistory(MaxHistory))
+
+	line, err := lineEditor.ReadLine("> ")
+	if line != "1 + 2" || err != nil {
+		t.Error("Expected:", "1 + 2", "Actual:", line, err)
+	}
+
+	line, err = lineEditor.ReadLine("> ")
+	if line != "3" || !errors.Is(err, io.EOF) {
+		t.Error("Expected:", "3", io.EOF, "Actual:", line, err)
+	}
+
+	if output.String() != "> > " {
+		t.Error("Expected:", "> > ", "Actual:", output.String())
+	}
+}
+
+func assertEditedLine(t *testing.T, historyEntries []string, keys string, expectedLine string) {
+	history := NewHistory(MaxHistory)
+	for _, entry := range historyEntries {
+		history.Add(entry)
+	}
+
+	lineEditor := NewLineEditor(strings.NewReader(keys), io.Discard, history)
+	line, err := lineEditor.edit("> ")
+	if err != nil {
+		t.Error("Expected:", nil, "Actual:", err)
+	}
+
+	if line != expectedLine {
+		t.Errorf("Keys: %q Expected: %q Actual: %q", keys, expectedLine, line)
+	}
+}
+
+func assertMatchingBracket(t *testing.T, buffer []rune, position int, expectedMatch int) {
+	match := findMatchingBracket(buffer, position)
+	if match != expectedMatch {
+		t.Error("Position:", position, "Expected:", expectedMatch, "Actual:", match)
+	}
+}
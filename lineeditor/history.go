@@ -0,0 +1,92 @@
+package lineeditor
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// Default maximum number of lines kept in the history.
+	MaxHistory = 1000
+)
+
+// Previously entered lines, oldest first.
+type History struct {
+	entries    []string
+	maxEntries int
+}
+
+func NewHistory(maxEntries int) *History {
+	history := History{}
+	history.maxEntries = maxEntries
+	return &history
+}
+
+// Gets the path of the history file in the user's config directory.
+func DefaultHistoryFile() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "gocalc", "history"), nil
+}
+
+// Adds a line to the history.  Blank lines and repeats of the previous line are not added, and the oldest lines are
+// dropped once the history is full.
+func (history *History) Add(line string) {
+	line = strings.TrimSpace(line)
+	if len(line) == 0 {
+		return
+	}
+
+	if len(history.entries) > 0 && history.entries[len(history.entries)-1] == line {
+		return
+	}
+
+	history.entries = append(history.entries, line)
+	if len(history.entries) > history.maxEntries {
+		history.entries = history.entries[len(history.entries)-history.maxEntries:]
+	}
+}
+
+func (history *History) GetEntries() []string {
+	return history.entries
+}
+
+// Adds the lines in the history file to the history.  A missing file is not an error, as there is no history yet.
+func (history *History) Load(fileName string) error {
+	file, err := os.Open(fileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		history.Add(scanner.Text())
+	}
+
+	return scanner.Err()
+}
+
+// Writes the history to the file, creating its directory if needed.
+func (history *History) Save(fileName string) error {
+	err := os.MkdirAll(filepath.Dir(fileName), 0700)
+	if err != nil {
+		return err
+	}
+
+	contents := ""
+	for _, entry := range history.entries {
+		contents += entry + "\n"
+	}
+
+	return os.WriteFile(fileName, []byte(contents), 0600)
+}
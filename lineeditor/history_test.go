@@ -0,0 +1,51 @@
+package lineeditor
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestHistoryAdd(t *testing.T) {
+	history := NewHistory(2)
+	history.Add("1")
+	history.Add("  ")
+	history.Add("2")
+	history.Add("2")
+	history.Add("3")
+
+	assertHistoryEntries(t, history, []string{"2", "3"})
+}
+
+func TestHistorySaveAndLoad(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "gocalc", "history")
+
+	history := NewHistory(MaxHistory)
+	err := history.Load(fileName)
+	if err != nil {
+		t.Error("Expected:", nil, "Actual:", err)
+	}
+
+	history.Add("$a = 1")
+	history.Add("$a * 2")
+	err = history.Save(fileName)
+	if err != nil {
+		t.Fatal("Expected:", nil, "Actual:", err)
+	}
+
+	loadedHistory := NewHistory(MaxHistory)
+	loadedHistory.Load(fileName)
+	assertHistoryEntries(t, loadedHistory, []string{"$a = 1", "$a * 2"})
+}
+
+func assertHistoryEntries(t *testing.T, history *History, expectedEntries []string) {
+	entries := history.GetEntries()
+	if len(entries) != len(expectedEntries) {
+		t.Fatal("Expected:", expectedEntries, "Actual:", entries)
+	}
+
+	for index, expectedEntry := range expectedEntries {
+		if entries[index] != expectedEntry {
+			t.Error("Expected:", expectedEntry, "Actual:", entries[index])
+		}
+	}
+}
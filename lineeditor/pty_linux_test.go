@@ -0,0 +1,111 @@
+package lineeditor
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// Opens a pseudo-terminal, returning the controlling side and the terminal side.
+func openPty(t *testing.T) (*os.File, *os.File) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		t.Skip("pseudo-terminals are not available:", err)
+	}
+
+	var unlock int32 = 0
+	var ptyNumber uint32
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, master.Fd(), syscall.TIOCSPTLCK, uintptr(unsafe.Pointer(&unlock))); errno != 0 {
+		t.Skip("pseudo-terminals are not available:", errno)
+	}
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, master.Fd(), syscall.TIOCGPTN, uintptr(unsafe.Pointer(&ptyNumber))); errno != 0 {
+		t.Skip("pseudo-terminals are not available:", errno)
+	}
+
+	slave, err := os.OpenFile(fmt.Sprintf("/dev/pts/%d", ptyNumber), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		t.Skip("pseudo-terminals are not available:", err)
+	}
+
+	t.Cleanup(func() {
+		slave.Close()
+		master.Close()
+	})
+
+	// Discard the output of the line editor so that it never blocks.
+	go io.Copy(io.Discard, master)
+
+	return master, slave
+}
+
+func TestLineEditorPty(t *testing.T) {
+	master, slave := openPty(t)
+
+	history := NewHistory(MaxHistory)
+	history.Add("2 ^ 10")
+	lineEditor := NewLineEditor(slave, slave, history)
+	if lineEditor.terminal == nil {
+		t.Fatal("Expected the pseudo-terminal to be detected as a terminal")
+	}
+
+	// In raw mode the keys are read as they are pressed, so the escape sequences are decoded rather than echoed.
+	line, err := readPtyLine(t, lineEditor, master, slave, "\x1b[A\x1b[D\x7f3\r")
+	if err != nil || line != "2 ^ 30" {
+		t.Error("Expected:", "2 ^ 30", "Actual:", line, err)
+	}
+
+	// The terminal mode is restored once the line has been read.
+	termios, err := getTermios(slave.Fd())
+	if err != nil {
+		t.Fatal("Expected:", nil, "Actual:", err)
+	}
+	if termios.Lflag&syscall.ICANON == 0 || termios.Lflag&syscall.ECHO == 0 {
+		t.Error("Expected the terminal to be back in canonical mode with echo")
+	}
+
+	_, err = readPtyLine(t, lineEditor, master, slave, "\x03")
+	if err != ErrInterrupted {
+		t.Error("Expected:", ErrInterrupted, "Actual:", err)
+	}
+}
+
+// Reads a line with the line editor, typing the keys once the terminal is in raw mode.  Keys typed before then would be
+// processed by the terminal itself.
+func readPtyLine(t *testing.T, lineEditor *LineEditor, master *os.File, slave *os.File, keys string) (string, error) {
+	type result struct {
+		line string
+		err  error
+	}
+
+	results := make(chan result)
+	go func() {
+		line, err := lineEditor.ReadLine("gocalc >> ")
+		results <- result{line, err}
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		termios, err := getTermios(slave.Fd())
+		if err == nil && termios.Lflag&syscall.ICANON == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for raw mode")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	master.Write([]byte(keys))
+
+	select {
+	case result := <-results:
+		return result.line, result.err
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the line")
+		return "", nil
+	}
+}
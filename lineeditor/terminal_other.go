@@ -0,0 +1,20 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package lineeditor
+
+import "errors"
+
+var errNotSupported = errors.New("terminal not supported")
+
+// Line editing is not supported on this platform, so input is always read a line at a time.
+func isTerminal(fd uintptr) bool {
+	return false
+}
+
+func makeRaw(fd uintptr) (func(), error) {
+	return nil, errNotSupported
+}
+
+func getWidth(fd uintptr) (int, error) {
+	return 0, errNotSupported
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"alanmitic/gocalc/command"
+	"alanmitic/gocalc/expreval"
+	"alanmitic/gocalc/resultformatter"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+var ErrUnknownMode = errors.New("unknown output mode")
+var ErrVariableSyntax = errors.New("variables must be given as name=value")
+var ErrInvalidPrecision = errors.New("precision must be from -1 to 17")
+
+// Default precision of each output mode, matching the commands that select them.
+var defaultPrecisions = map[resultformatter.OutputMode]int{
+	resultformatter.OutputModeFixed:      command.DefaultFixPrecision,
+	resultformatter.OutputModeReal:       command.DefaultRealPrecision,
+	resultformatter.OutputModeScientific: command.DefaultSciPrecision,
+}
+
+// Flag that may be repeated, collecting each value.
+type stringList []string
+
+func (values *stringList) String() string {
+	return strings.Join(*values, ", ")
+}
+
+func (values *stringList) Set(value string) error {
+	*values = append(*values, value)
+	return nil
+}
+
+// Command line options.
+type options struct {
+	expressions stringList
+	variables   stringList
+	mode        string
+	precision   int
+	autosave    bool
+	rcFile      string
+	norc        bool
+	scriptFile  string
+}
+
+func parseOptions(arguments []string) (*options, error) {
+	options := options{}
+
+	flagSet := flag.NewFlagSet("gocalc", flag.ContinueOnError)
+	flagSet.Usage = func() {
+		fmt.Fprintln(flagSet.Output(), "Usage: gocalc [options] [script file]")
+		flagSet.PrintDefaults()
+	}
+	flagSet.Var(&options.expressions, "e", "evaluate the expression or command, print the result and exit (repeatable)")
+	flagSet.Var(&options.variables, "var", "set a variable before evaluating, e.g. x=3 (repeatable)")
+	flagSet.StringVar(&options.mode, "mode", "", "output mode: fix, real, sci, bin, oct or hex")
+	flagSet.IntVar(&options.precision, "precision", -2, "output precision")
+	flagSet.BoolVar(&options.autosave, "autosave", false, "load the saved state at start and save it on exit")
+	flagSet.StringVar(&options.rcFile, "rc", "", "run the file at startup instead of the default rc file")
+	flagSet.BoolVar(&options.norc, "norc", false, "do not run an rc file at startup")
+
+	err := flagSet.Parse(arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	if flagSet.NArg() > 1 {
+		flagSet.Usage()
+		return nil, command.ErrTooManyArgs
+	}
+	options.scriptFile = flagSet.Arg(0)
+
+	return &options, nil
+}
+
+// Configures the result formatter and variable store from the options.
+func (options *options) apply(evaluator *expreval.Evaluator, resultFormatter resultformatter.ResultFormatter) error {
+	if options.mode != "" {
+		outputMode, found := command.OutputModes[options.mode]
+		if !found {
+			return fmt.Errorf("%w: %s", ErrUnknownMode, options.mode)
+		}
+
+		resultFormatter.SetOutputMode(outputMode)
+		if precision, found := defaultPrecisions[outputMode]; found {
+			resultFormatter.SetPrecision(precision)
+		} else {
+			resultFormatter.SetPrecision(-1)
+		}
+	}
+
+	// A precision of -1 is meaningful, so -2 is used to show that it was not given.
+	if options.precision != -2 {
+		if options.precision < -1 || options.precision > resultformatter.MaxPrecision {
+			return fmt.Errorf("%w: %d", ErrInvalidPrecision, options.precision)
+		}
+		resultFormatter.SetPrecision(options.precision)
+	}
+
+	// Setting the variables should not leave a result in $ans or the history.
+	_, ansFound := evaluator.Variables.GetVariable("$ans")
+	history := evaluator.History
+	defer func() {
+		evaluator.History = history
+		if !ansFound {
+			evaluator.Variables.DeleteVariable("$ans")
+		}
+	}()
+
+	for _, variable := range options.variables {
+		name, value, found := strings.Cut(variable, "=")
+		if !found {
+			return fmt.Errorf("%w: %s", ErrVariableSyntax, variable)
+		}
+
+		name = "$" + strings.TrimPrefix(strings.TrimSpace(name), "$")
+		_, err := evaluator.Evaluate(name + " = " + value)
+		if err != nil {
+			return fmt.Errorf("--var %s: %w", variable, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"alanmitic/gocalc/expreval"
+	"alanmitic/gocalc/resultformatter"
+	"alanmitic/gocalc/session"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOptionsApply(t *testing.T) {
+	options, err := parseOptions([]string{"--mode", "fix", "--precision", "4", "--var", "x=3", "--var", "$y = $x * 2",
+		"-e", "$y"})
+	if err != nil {
+		t.Fatal("Expected:", nil, "Actual:", err)
+	}
+
+	evaluator := expreval.NewEvaluator()
+	resultFormatter := resultformatter.NewResultFormatter()
+	err = options.apply(evaluator, resultFormatter)
+	if err != nil {
+		t.Fatal("Expected:", nil, "Actual:", err)
+	}
+
+	if resultFormatter.GetOutputMode() != resultformatter.OutputModeFixed {
+		t.Error("Expected:", resultformatter.OutputModeFixed, "Actual:", resultFormatter.GetOutputMode())
+	}
+	if resultFormatter.GetPrecision() != 4 {
+		t.Error("Expected:", 4, "Actual:", resultFormatter.GetPrecision())
+	}
+	if value, _ := evaluator.Variables.GetVariable("$y"); value != 6 {
+		t.Error("Expected:", 6, "Actual:", value)
+	}
+	if _, found := evaluator.Variables.GetVariable("$ans"); found {
+		t.Error("Variable should not be defined: $ans")
+	}
+	if len(options.expressions) != 1 || options.expressions[0] != "$y" {
+		t.Error("Expected:", "$y", "Actual:", options.expressions)
+	}
+}
+
+func TestOptionsModeDefaultPrecision(t *testing.T) {
+	options, _ := parseOptions([]string{"--mode", "sci"})
+	resultFormatter := resultformatter.NewResultFormatter()
+	options.apply(expreval.NewEvaluator(), resultFormatter)
+
+	if resultFormatter.GetPrecision() != 2 {
+		t.Error("Expected:", 2, "Actual:", resultFormatter.GetPrecision())
+	}
+}
+
+func TestOptionsInvalid(t *testing.T) {
+	options, _ := parseOptions([]string{"--mode", "dec"})
+	err := options.apply(expreval.NewEvaluator(), resultformatter.NewResultFormatter())
+	if !errors.Is(err, ErrUnknownMode) {
+		t.Error("Expected:", ErrUnknownMode, "Actual:", err)
+	}
+
+	options, _ = parseOptions([]string{"--precision", "100"})
+	err = options.apply(expreval.NewEvaluator(), resultformatter.NewResultFormatter())
+	if !errors.Is(err, ErrInvalidPrecision) {
+		t.Error("Expected:", ErrInvalidPrecision, "Actual:", err)
+	}
+
+	options, _ = parseOptions([]string{"--var", "x"})
+	err = options.apply(expreval.NewEvaluator(), resultformatter.NewResultFormatter())
+	if !errors.Is(err, ErrVariableSyntax) {
+		t.Error("Expected:", ErrVariableSyntax, "Actual:", err)
+	}
+}
+
+func TestGetRCFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	options, _ := parseOptions([]string{"--rc", "test.rc"})
+	if getRCFile(options) != "test.rc" {
+		t.Error("Expected:", "test.rc", "Actual:", getRCFile(options))
+	}
+
+	options, _ = parseOptions([]string{"--rc", "test.rc", "--norc"})
+	if getRCFile(options) != "" {
+		t.Error("Expected:", "", "Actual:", getRCFile(options))
+	}
+
+	// The default rc file is only run if it exists.
+	options, _ = parseOptions([]string{})
+	if getRCFile(options) != "" {
+		t.Error("Expected:", "", "Actual:", getRCFile(options))
+	}
+
+	rcFile, _ := session.DefaultRCFile()
+	os.MkdirAll(filepath.Dir(rcFile), 0700)
+	os.WriteFile(rcFile, []byte("fix 4\n"), 0600)
+	if getRCFile(options) != rcFile {
+		t.Error("Expected:", rcFile, "Actual:", getRCFile(options))
+	}
+}
@@ -2,6 +2,7 @@ package resultformatter
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 )
 
@@ -17,6 +18,10 @@ const (
 	OutputModeHexadecimal
 )
 
+// Largest precision that is meaningful, as a float64 has at most 17 significant digits.  A precision of -1 uses the
+// fewest digits that represent the value.
+const MaxPrecision = 17
+
 type ResultFormatter interface {
 	GetOutputMode() OutputMode
 	SetOutputMode(outputMode OutputMode)
@@ -54,6 +59,16 @@ func (resultFormatter *ResultFormatterImpl) SetPrecision(precision int) {
 }
 
 func (resultFormatter *ResultFormatterImpl) FormatValue(value float64) string {
+	// Special values are formatted the same in every output mode, and match the literals accepted by the evaluator.
+	switch {
+	case math.IsNaN(value):
+		return "nan"
+	case math.IsInf(value, 1):
+		return "inf"
+	case math.IsInf(value, -1):
+		return "-inf"
+	}
+
 	formattedValue := ""
 
 	switch resultFormatter.outputMode {
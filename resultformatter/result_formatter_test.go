@@ -1,6 +1,7 @@
 package resultformatter
 
 import (
+	"math"
 	"testing"
 )
 
@@ -134,6 +135,17 @@ func TestResultFormatterFormatHexadecimal(t *testing.T) {
 	assertFormattedValue(t, resultFormatter, 2147483647, "7fffffff")
 }
 
+func TestResultFormatterFormatSpecialValues(t *testing.T) {
+	resultFormatter := NewResultFormatter()
+	for _, outputMode := range []OutputMode{OutputModeFixed, OutputModeReal, OutputModeScientific, OutputModeBinary,
+		OutputModeOctal, OutputModeHexadecimal} {
+		resultFormatter.SetOutputMode(outputMode)
+		assertFormattedValue(t, resultFormatter, math.NaN(), "nan")
+		assertFormattedValue(t, resultFormatter, math.Inf(1), "inf")
+		assertFormattedValue(t, resultFormatter, math.Inf(-1), "-inf")
+	}
+}
+
 func assertFormattedValue(t *testing.T, resultFormatter ResultFormatter, inputValue float64, expectedFormattedValue string) {
 	formattedValue := resultFormatter.FormatValue(inputValue)
 	if formattedValue != expectedFormattedValue {
@@ -0,0 +1,44 @@
+package session
+
+import (
+	"alanmitic/gocalc/command"
+	"alanmitic/gocalc/expreval"
+	"math"
+)
+
+// Mathematical functions registered for every session, e.g. "sin($x)".  Angles are in radians.
+var mathFunctions = []struct {
+	name     string
+	arity    int
+	function expreval.Function
+}{
+	{"abs", 1, unaryFunction(math.Abs)},
+	{"sqrt", 1, unaryFunction(math.Sqrt)},
+	{"exp", 1, unaryFunction(math.Exp)},
+	{"ln", 1, unaryFunction(math.Log)},
+	{"log", 1, unaryFunction(math.Log10)},
+	{"sin", 1, unaryFunction(math.Sin)},
+	{"cos", 1, unaryFunction(math.Cos)},
+	{"tan", 1, unaryFunction(math.Tan)},
+	{"asin", 1, unaryFunction(math.Asin)},
+	{"acos", 1, unaryFunction(math.Acos)},
+	{"atan", 1, unaryFunction(math.Atan)},
+	{"floor", 1, unaryFunction(math.Floor)},
+	{"ceil", 1, unaryFunction(math.Ceil)},
+	{"round", 1, unaryFunction(math.Round)},
+}
+
+// Makes a function of one argument callable from an expression.  A NaN or infinite result, such as sqrt(-1), is
+// treated according to the evaluator's non-finite policy.
+func unaryFunction(function func(float64) float64) expreval.Function {
+	return func(arguments []float64) (float64, error) {
+		return function(arguments[0]), nil
+	}
+}
+
+// Registers the mathematical functions.  The names are valid and not already used, so registering them cannot fail.
+func registerMathFunctions(commandParser *command.CommandParser) {
+	for _, mathFunction := range mathFunctions {
+		commandParser.RegisterFunction(mathFunction.name, mathFunction.arity, mathFunction.function)
+	}
+}
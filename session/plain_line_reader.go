@@ -0,0 +1,67 @@
+package session
+
+import (
+	"alanmitic/gocalc/lineeditor"
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Line of input read by the plain line reader.
+type inputLine struct {
+	text string
+	err  error
+}
+
+// Reads lines from the input without editing.  Lines are read in the background so that an interrupt can abandon the
+// line being typed.
+type plainLineReader struct {
+	output     io.Writer
+	lines      chan inputLine
+	interrupts chan struct{}
+	done       chan struct{}
+}
+
+func newPlainLineReader(input io.Reader, output io.Writer, interrupts chan struct{}) *plainLineReader {
+	lineReader := plainLineReader{}
+	lineReader.output = output
+	lineReader.lines = make(chan inputLine)
+	lineReader.interrupts = interrupts
+	lineReader.done = make(chan struct{})
+
+	go func() {
+		reader := bufio.NewReader(input)
+		for {
+			text, err := reader.ReadString('\n')
+			select {
+			case lineReader.lines <- inputLine{strings.TrimRight(text, "\r\n"), err}:
+			case <-lineReader.done:
+				return
+			}
+
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	return &lineReader
+}
+
+func (lineReader *plainLineReader) ReadLine(prompt string) (string, error) {
+	fmt.Fprint(lineReader.output, prompt)
+
+	select {
+	case <-lineReader.interrupts:
+		fmt.Fprintln(lineReader.output)
+		return "", lineeditor.ErrInterrupted
+	case line := <-lineReader.lines:
+		return line.text, line.err
+	}
+}
+
+// Stops reading in the background.
+func (lineReader *plainLineReader) close() {
+	close(lineReader.done)
+}
@@ -0,0 +1,192 @@
+package session
+
+import (
+	"alanmitic/gocalc/command"
+	"alanmitic/gocalc/expreval"
+	"alanmitic/gocalc/lineeditor"
+	"alanmitic/gocalc/resultformatter"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	Banner = "gocalc version 0.0.3\n\nType 'help' and ENTER for help or 'exit' and ENTER to quit"
+	// Prompt shown until it is changed by the prompt command.
+	Prompt = command.DefaultPrompt
+)
+
+// A calculator session that reads input from a reader and writes results to a writer, so that it can be driven by the
+// terminal, a script, a test or any other program.
+type Session struct {
+	evaluator       *expreval.Evaluator
+	resultFormatter resultformatter.ResultFormatter
+	commandParser   *command.CommandParser
+	scriptRunner    *command.ScriptRunner
+	input           io.Reader
+	output          io.Writer
+	lineReader      LineReader
+	interrupts      chan struct{}
+}
+
+// Reads lines of input for the prompt, e.g. a line editor.
+type LineReader interface {
+	// Prints the prompt and reads a line, without the line ending.  Returns io.EOF at the end of the input, and
+	// lineeditor.ErrInterrupted when the line is abandoned.
+	ReadLine(prompt string) (string, error)
+}
+
+// Creates a session reading from input, and writing results and errors to output.
+func NewSession(input io.Reader, output io.Writer) *Session {
+	session := Session{}
+	session.evaluator = expreval.NewEvaluator()
+	session.resultFormatter = resultformatter.NewResultFormatter()
+	session.commandParser = command.NewCommandParser(session.evaluator, session.resultFormatter)
+	session.scriptRunner = session.commandParser.GetScriptRunner()
+	registerMathFunctions(session.commandParser)
+	session.input = input
+	session.output = output
+	session.interrupts = make(chan struct{}, 1)
+	session.SetErrorOutput(output)
+	session.scriptRunner.Output = output
+	return &session
+}
+
+func (session *Session) GetEvaluator() *expreval.Evaluator {
+	return session.evaluator
+}
+
+func (session *Session) GetResultFormatter() resultformatter.ResultFormatter {
+	return session.resultFormatter
+}
+
+func (session *Session) GetCommandParser() *command.CommandParser {
+	return session.commandParser
+}
+
+// Sets where errors are written, e.g. so that only results are written to the output.
+func (session *Session) SetErrorOutput(errorOutput io.Writer) {
+	session.scriptRunner.ErrorOutput = errorOutput
+}
+
+// Sets the reader of lines for the prompt.  By default lines are read from the session input without editing.
+func (session *Session) SetLineReader(lineReader LineReader) {
+	session.lineReader = lineReader
+}
+
+// Executes a line as a command, or otherwise evaluates it as an expression and writes the result.
+func (session *Session) ExecuteLine(line string) error {
+	return session.scriptRunner.ExecuteLine(line)
+}
+
+// Prints the error from executing the input line to the error output.
+func (session *Session) PrintError(err error, input string) {
+	command.PrintError(session.scriptRunner.ErrorOutput, err, input)
+}
+
+// Runs each line of the session input as a script.
+func (session *Session) RunScript(fileName string) error {
+	return session.scriptRunner.Run(session.input, fileName)
+}
+
+// Runs the script in the named file.
+func (session *Session) RunFile(fileName string) error {
+	return session.scriptRunner.RunFile(fileName)
+}
+
+// Runs a startup file, such as the rc file, without writing the results or keeping them in the history.  Errors are
+// written to the error output with their line numbers and the rest of the file is still run.
+func (session *Session) RunStartupFile(fileName string) error {
+	output := session.scriptRunner.Output
+	history := session.evaluator.History
+	session.scriptRunner.Output = io.Discard
+	defer func() {
+		session.scriptRunner.Output = output
+		session.evaluator.History = history
+	}()
+
+	return session.scriptRunner.RunFile(fileName)
+}
+
+// Gets the path of the default rc file, run at startup, in the user's configuration directory.
+func DefaultRCFile() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "gocalc", "gocalcrc"), nil
+}
+
+// Requests that the current input is discarded, or any running script is stopped.  This is safe to call from another
+// goroutine, such as a signal handler.
+func (session *Session) Interrupt() {
+	session.scriptRunner.Interrupt()
+	select {
+	case session.interrupts <- struct{}{}:
+	default:
+	}
+}
+
+// Prompts for and executes lines of input until exit or the end of the input.  Results are printed with their history
+// number.  Errors executing a line are written to the error output, and the prompt continues.  The error from the exit
+// command is returned so that the caller can clean up before exiting.
+func (session *Session) RunPrompt() error {
+	lineReader := session.lineReader
+	if lineReader == nil {
+		plainLineReader := newPlainLineReader(session.input, session.output, session.interrupts)
+		defer plainLineReader.close()
+		lineReader = plainLineReader
+	}
+
+	// Results at the prompt are numbered so that they can be referred to, e.g. as $_3.
+	numberResults := session.scriptRunner.NumberResults
+	session.scriptRunner.NumberResults = true
+	defer func() {
+		session.scriptRunner.NumberResults = numberResults
+	}()
+
+	fmt.Fprintln(session.output, Banner)
+
+	for {
+		prompt := session.scriptRunner.Prompt
+		if session.scriptRunner.IsRecordingMacro() {
+			prompt = command.MacroPrompt
+		}
+
+		line, err := lineReader.ReadLine(prompt)
+		if errors.Is(err, lineeditor.ErrInterrupted) {
+			// An interrupt also abandons a macro being recorded.
+			session.scriptRunner.CancelMacro()
+			continue
+		}
+
+		if err == nil || len(line) > 0 {
+			session.scriptRunner.ClearInterrupt()
+			err := session.ExecuteLine(line)
+			if errors.Is(err, command.ErrExit) {
+				return err
+			}
+
+			if err != nil {
+				session.PrintError(err, line)
+			}
+
+			// An interrupt while the line was executing has been handled.
+			select {
+			case <-session.interrupts:
+			default:
+			}
+		}
+
+		if err != nil {
+			// The end of the input (Ctrl-D) ends the session.
+			fmt.Fprintln(session.output)
+			if err != io.EOF {
+				return err
+			}
+			return nil
+		}
+	}
+}
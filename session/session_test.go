@@ -0,0 +1,123 @@
+package session
+
+import (
+	"alanmitic/gocalc/command"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSessionRunPrompt(t *testing.T) {
+	output := new(bytes.Buffer)
+	session := NewSession(strings.NewReader("$a = 1 + 2\nhex\n$a * 16\n1 +\n"), output)
+
+	err := session.RunPrompt()
+	if err != nil {
+		t.Error("Expected:", nil, "Actual:", err)
+	}
+
+	expectedOutput := Banner + "\n" +
+		Prompt + "[1] 3\n" +
+		Prompt +
+		Prompt + "[2] 00000030\n" +
+		Prompt + "ERROR: primary expected at column 4\n  1 +\n     ^\n" +
+		Prompt + "\n"
+	if output.String() != expectedOutput {
+		t.Error("Expected:", expectedOutput, "Actual:", output.String())
+	}
+}
+
+func TestSessionRunPromptExit(t *testing.T) {
+	output := new(bytes.Buffer)
+	session := NewSession(strings.NewReader("exit 5\n1 + 2\n"), output)
+
+	err := session.RunPrompt()
+	var exitError *command.ExitError
+	if !errors.As(err, &exitError) || exitError.Code != 5 {
+		t.Error("Expected:", "exit 5", "Actual:", err)
+	}
+
+	if output.String() != Banner+"\n"+Prompt {
+		t.Error("Expected:", Banner+"\n"+Prompt, "Actual:", output.String())
+	}
+}
+
+func TestSessionRunScript(t *testing.T) {
+	output := new(bytes.Buffer)
+	errorOutput := new(bytes.Buffer)
+	session := NewSession(strings.NewReader("2 ^ 10\n$undefined\n"), output)
+	session.SetErrorOutput(errorOutput)
+
+	err := session.RunScript("test.gc")
+	if err != command.ErrScriptFailed {
+		t.Error("Expected:", command.ErrScriptFailed, "Actual:", err)
+	}
+
+	if output.String() != "1024\n" {
+		t.Error("Expected:", "1024\n", "Actual:", output.String())
+	}
+
+	if !strings.HasPrefix(errorOutput.String(), "ERROR: test.gc:2: undefined variable $undefined") {
+		t.Error("Expected:", "ERROR: test.gc:2: ...", "Actual:", errorOutput.String())
+	}
+}
+
+func TestSessionExecuteLine(t *testing.T) {
+	output := new(bytes.Buffer)
+	session := NewSession(strings.NewReader(""), output)
+
+	session.ExecuteLine("fix 2")
+	session.ExecuteLine("1 / 3")
+	if output.String() != "0.33\n" {
+		t.Error("Expected:", "0.33\n", "Actual:", output.String())
+	}
+
+	if ans, _ := session.GetEvaluator().Variables.GetVariable("$ans"); ans != 1.0/3.0 {
+		t.Error("Expected:", 1.0/3.0, "Actual:", ans)
+	}
+}
+
+func TestSessionMathFunctions(t *testing.T) {
+	output := new(bytes.Buffer)
+	session := NewSession(strings.NewReader(""), output)
+
+	session.ExecuteLine("fix 4")
+	session.ExecuteLine("sin(pi / 6) + sqrt(16) - ln(exp(2))")
+	if output.String() != "2.5000\n" {
+		t.Error("Expected:", "2.5000\n", "Actual:", output.String())
+	}
+
+	err := session.ExecuteLine("plot sin($x), $x, -pi, pi")
+	if err != nil {
+		t.Error("Expected:", nil, "Actual:", err)
+	}
+}
+
+func TestSessionRunStartupFile(t *testing.T) {
+	rcFile := filepath.Join(t.TempDir(), "gocalcrc")
+	os.WriteFile(rcFile, []byte("fix 4\n$g = 9.80665\n$bad = 1 +\nprompt \"calc> \"\n"), 0600)
+
+	output := new(bytes.Buffer)
+	errorOutput := new(bytes.Buffer)
+	session := NewSession(strings.NewReader("$g * 2\n"), output)
+	session.SetErrorOutput(errorOutput)
+
+	err := session.RunStartupFile(rcFile)
+	if err != command.ErrScriptFailed {
+		t.Error("Expected:", command.ErrScriptFailed, "Actual:", err)
+	}
+
+	if !strings.HasPrefix(errorOutput.String(), "ERROR: "+rcFile+":3: primary expected") {
+		t.Error("Expected:", "ERROR: gocalcrc:3: ...", "Actual:", errorOutput.String())
+	}
+
+	// The rest of the file is run, without writing results or keeping them in the history.
+	session.RunPrompt()
+	expectedOutput := Banner + "\ncalc> [1] 19.6133\ncalc> \n"
+	if output.String() != expectedOutput {
+		t.Error("Expected:", expectedOutput, "Actual:", output.String())
+	}
+}